@@ -0,0 +1,368 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// Annotation flags relevant for rendering (PDF 32000-1:2008, 12.5.3)
+const (
+	annotFlagHidden = 1 << 1
+	annotFlagNoView = 1 << 5
+)
+
+// pdfAnnotation is an annotation in PDF user space, before mapping to output pixels
+type pdfAnnotation struct {
+	Annotation
+	rect [4]float64 // llx, lly, urx, ury in points
+	box  [4]float64 // visible page box the rect is relative to
+}
+
+// renders reports whether an annotation of this kind is drawn with the given options
+func (a *pdfAnnotation) renders(opts *Options) bool {
+	if a.Hidden {
+		return false
+	}
+	switch {
+	case a.Type == "Widget" && a.FieldType == "Sig":
+		return opts.RenderSignatures
+	case a.Type == "Widget":
+		return opts.RenderFormFields
+	case a.Type == "Popup" || a.Type == "Link":
+		return false
+	default:
+		return opts.RenderAnnotations
+	}
+}
+
+// toPixels maps the annotation rectangle into output PNG pixels at the given scale,
+// relative to the crop offset
+func (a *pdfAnnotation) toPixels(scale float64, cropInfo CropInfo) Annotation {
+	out := a.Annotation
+	out.X = int(math.Round((a.rect[0]-a.box[0])*scale)) - cropInfo.OffsetX
+	out.Y = int(math.Round((a.box[3]-a.rect[3])*scale)) - cropInfo.OffsetY
+	out.Width = int(math.Round((a.rect[2] - a.rect[0]) * scale))
+	out.Height = int(math.Round((a.rect[3] - a.rect[1]) * scale))
+	return out
+}
+
+// readPdfAnnotations reads the annotations of every page from raw, or from the file when
+// raw is nil. If any render option is set, the appearance streams of the selected
+// annotations are flattened into the page content and the modified PDF is returned.
+func readPdfAnnotations(filename string, raw []byte, opts *Options) ([][]pdfAnnotation, []byte, error) {
+	ctx, err := readPdfContext(filename, raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pages := make([][]pdfAnnotation, ctx.PageCount)
+	flattened := false
+
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		pageDict, _, inherited, err := ctx.PageDict(pageNr, false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		box := pageBox(inherited)
+		annots, err := ctx.DereferenceArray(pageDict["Annots"])
+		if err != nil {
+			slog.Warn("readPdfAnnotations: annotations array", "page", pageNr, "error", err)
+			continue
+		}
+
+		var content bytes.Buffer
+		xobjects := types.NewDict()
+
+		for _, obj := range annots {
+			annot, err := ctx.DereferenceDict(obj)
+			if err != nil || annot == nil {
+				continue
+			}
+
+			a := parseAnnotation(ctx, annot, box)
+			if a.renders(opts) {
+				name := fmt.Sprintf("FlatAnnot%d", len(xobjects)+1)
+				ref := appearanceStream(ctx, annot)
+				if ops := flattenOperators(ctx, ref, a.rect, name); ops != "" {
+					xobjects[name] = ref
+					content.WriteString(ops)
+					a.Rendered = true
+				}
+			}
+			pages[pageNr-1] = append(pages[pageNr-1], a)
+		}
+
+		if len(xobjects) > 0 {
+			if err := addPageXObjects(ctx, pageDict, inherited, xobjects); err != nil {
+				return nil, nil, err
+			}
+			if err := ctx.AppendContent(pageDict, content.Bytes()); err != nil {
+				return nil, nil, err
+			}
+			flattened = true
+		}
+	}
+
+	if !flattened {
+		return pages, nil, nil
+	}
+
+	var out bytes.Buffer
+	if err := api.WriteContext(ctx, &out); err != nil {
+		return nil, nil, err
+	}
+	return pages, out.Bytes(), nil
+}
+
+// readPdfContext parses a PDF with pdfcpu from raw, or from the file when raw is nil
+func readPdfContext(filename string, raw []byte) (*model.Context, error) {
+	disablePdfcpuConfig.Do(api.DisableConfigDir)
+
+	var rs io.ReadSeeker
+	if raw != nil {
+		rs = bytes.NewReader(raw)
+	} else {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				slog.Warn("Failed to close PDF file", "error", err)
+			}
+		}()
+		rs = file
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	ctx, err := api.ReadContext(rs, conf)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.EnsurePageCount(); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// parseAnnotation extracts type, rectangle, contents and form field details of an annotation
+func parseAnnotation(ctx *model.Context, annot types.Dict, box [4]float64) pdfAnnotation {
+	a := pdfAnnotation{box: box}
+	if subtype := annot.NameEntry("Subtype"); subtype != nil {
+		a.Type = *subtype
+	}
+	a.rect = rectEntry(ctx, annot, "Rect")
+	if flags := annot.IntEntry("F"); flags != nil {
+		a.Hidden = *flags&(annotFlagHidden|annotFlagNoView) != 0
+	}
+	if contents, err := ctx.DereferenceText(annot["Contents"]); err == nil {
+		a.Contents = contents
+	}
+
+	if a.Type == "Widget" {
+		a.FieldName = fieldName(ctx, annot)
+		if ft, ok := inheritedFieldEntry(ctx, annot, "FT").(types.Name); ok {
+			a.FieldType = ft.Value()
+		}
+		a.FieldValue = fieldValue(ctx, inheritedFieldEntry(ctx, annot, "V"))
+	}
+
+	return a
+}
+
+// fieldName returns the fully qualified name of a form field widget
+func fieldName(ctx *model.Context, d types.Dict) string {
+	var parts []string
+	for depth := 0; d != nil && depth < 32; depth++ {
+		if t, err := ctx.DereferenceText(d["T"]); err == nil && t != "" {
+			parts = append([]string{t}, parts...)
+		}
+		parent, err := ctx.DereferenceDict(d["Parent"])
+		if err != nil {
+			break
+		}
+		d = parent
+	}
+	return strings.Join(parts, ".")
+}
+
+// inheritedFieldEntry looks up an inheritable form field entry on the widget or its ancestors
+func inheritedFieldEntry(ctx *model.Context, d types.Dict, key string) types.Object {
+	for depth := 0; d != nil && depth < 32; depth++ {
+		if obj, found := d.Find(key); found {
+			o, err := ctx.Dereference(obj)
+			if err != nil {
+				return nil
+			}
+			return o
+		}
+		parent, err := ctx.DereferenceDict(d["Parent"])
+		if err != nil {
+			return nil
+		}
+		d = parent
+	}
+	return nil
+}
+
+// fieldValue formats a form field value as text
+func fieldValue(ctx *model.Context, v types.Object) string {
+	switch value := v.(type) {
+	case types.Name:
+		return value.Value()
+	case types.StringLiteral, types.HexLiteral:
+		s, _ := model.Text(value)
+		return s
+	case types.Array:
+		var values []string
+		for _, item := range value {
+			if o, err := ctx.Dereference(item); err == nil {
+				values = append(values, fieldValue(ctx, o))
+			}
+		}
+		return strings.Join(values, ", ")
+	default:
+		return ""
+	}
+}
+
+// appearanceStream returns a reference to the normal appearance of an annotation,
+// selecting the current state for widgets with several appearance states
+func appearanceStream(ctx *model.Context, annot types.Dict) types.Object {
+	ap, err := ctx.DereferenceDict(annot["AP"])
+	if err != nil || ap == nil {
+		return nil
+	}
+
+	normal, found := ap.Find("N")
+	if !found {
+		return nil
+	}
+	if sd, _, err := ctx.DereferenceStreamDict(normal); err == nil && sd != nil {
+		return normal
+	}
+
+	states, err := ctx.DereferenceDict(normal)
+	if err != nil || states == nil {
+		return nil
+	}
+	state := annot.NameEntry("AS")
+	if state == nil {
+		return nil
+	}
+	stream, found := states.Find(*state)
+	if !found {
+		return nil
+	}
+	return stream
+}
+
+// flattenOperators returns the content stream operators that draw an appearance
+// stream, registered as name, into the annotation rectangle
+func flattenOperators(ctx *model.Context, ref types.Object, rect [4]float64, name string) string {
+	if ref == nil {
+		return ""
+	}
+	sd, _, err := ctx.DereferenceStreamDict(ref)
+	if err != nil || sd == nil {
+		return ""
+	}
+
+	bbox := rectEntry(ctx, sd.Dict, "BBox")
+	matrix := [6]float64{1, 0, 0, 1, 0, 0}
+	if arr, err := ctx.DereferenceArray(sd.Dict["Matrix"]); err == nil && len(arr) == 6 {
+		for i, o := range arr {
+			matrix[i], _ = ctx.DereferenceNumber(o)
+		}
+	}
+
+	// Transform the bounding box by the form matrix and map the result onto the
+	// annotation rectangle (PDF 32000-1:2008, 12.5.5)
+	x0, y0 := math.Inf(1), math.Inf(1)
+	x1, y1 := math.Inf(-1), math.Inf(-1)
+	for _, corner := range [][2]float64{{bbox[0], bbox[1]}, {bbox[2], bbox[1]}, {bbox[0], bbox[3]}, {bbox[2], bbox[3]}} {
+		x := matrix[0]*corner[0] + matrix[2]*corner[1] + matrix[4]
+		y := matrix[1]*corner[0] + matrix[3]*corner[1] + matrix[5]
+		x0, y0 = math.Min(x0, x), math.Min(y0, y)
+		x1, y1 = math.Max(x1, x), math.Max(y1, y)
+	}
+	if x1-x0 <= 0 || y1-y0 <= 0 {
+		return ""
+	}
+
+	sx := (rect[2] - rect[0]) / (x1 - x0)
+	sy := (rect[3] - rect[1]) / (y1 - y0)
+	return fmt.Sprintf("q %.4f 0 0 %.4f %.4f %.4f cm /%s Do Q\n", sx, sy, rect[0]-x0*sx, rect[1]-y0*sy, name)
+}
+
+// addPageXObjects adds form XObjects to the page's own resources, copying inherited
+// resources so shared resource dictionaries are left untouched
+func addPageXObjects(ctx *model.Context, pageDict types.Dict, inherited *model.InheritedPageAttrs, xobjects types.Dict) error {
+	resources, err := ctx.DereferenceDict(pageDict["Resources"])
+	if err != nil {
+		return err
+	}
+	if resources == nil && inherited != nil {
+		resources = inherited.Resources
+	}
+
+	merged := types.NewDict()
+	for k, v := range resources {
+		merged[k] = v
+	}
+
+	existing, err := ctx.DereferenceDict(merged["XObject"])
+	if err != nil {
+		return err
+	}
+	allXObjects := types.NewDict()
+	for k, v := range existing {
+		allXObjects[k] = v
+	}
+	for k, v := range xobjects {
+		allXObjects[k] = v
+	}
+
+	merged["XObject"] = allXObjects
+	pageDict["Resources"] = merged
+	return nil
+}
+
+// pageBox returns the visible page box (crop box, else media box) of a page
+func pageBox(inherited *model.InheritedPageAttrs) [4]float64 {
+	if inherited == nil {
+		return [4]float64{}
+	}
+	r := inherited.CropBox
+	if r == nil {
+		r = inherited.MediaBox
+	}
+	if r == nil {
+		return [4]float64{}
+	}
+	return [4]float64{r.LL.X, r.LL.Y, r.UR.X, r.UR.Y}
+}
+
+// rectEntry reads a normalized rectangle array entry from a dictionary
+func rectEntry(ctx *model.Context, d types.Dict, key string) [4]float64 {
+	var r [4]float64
+	arr, err := ctx.DereferenceArray(d[key])
+	if err != nil || len(arr) != 4 {
+		return r
+	}
+	for i, o := range arr {
+		r[i], _ = ctx.DereferenceNumber(o)
+	}
+	return [4]float64{math.Min(r[0], r[2]), math.Min(r[1], r[3]), math.Max(r[0], r[2]), math.Max(r[1], r[3])}
+}
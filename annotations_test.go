@@ -0,0 +1,105 @@
+package tifpdf2png
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// writeAnnotatedTestPdf writes a Letter-sized page with a square markup annotation at
+// [100 600 200 700] and a filled text field widget at [100 400 300 430], both with
+// solid black appearance streams
+func writeAnnotatedTestPdf(t *testing.T, destPath string) {
+	t.Helper()
+
+	writeRawTestPdf(t, destPath, []string{
+		"<< /Type /Catalog /Pages 2 0 R /AcroForm << /Fields [7 0 R] >> >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 5 0 R /Resources << /Font << /F1 3 0 R >> >> /Annots [6 0 R 7 0 R] >>",
+		testPdfStream("", "BT /F1 24 Tf 72 720 Td (Review copy) Tj ET"),
+		"<< /Type /Annot /Subtype /Square /Rect [100 600 200 700] /Contents (Check total) /AP << /N 8 0 R >> >>",
+		"<< /Type /Annot /Subtype /Widget /FT /Tx /T (applicant) /V (Jane Doe) /Rect [100 400 300 430] /P 4 0 R /AP << /N 9 0 R >> >>",
+		testPdfStream("/Type /XObject /Subtype /Form /BBox [0 0 50 50]", "0 0 0 rg 0 0 50 50 re f"),
+		testPdfStream("/Type /XObject /Subtype /Form /BBox [0 0 200 30]", "0 0 0 rg 0 0 200 30 re f"),
+	})
+}
+
+func TestExtractPdfAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "annotated.pdf")
+	writeAnnotatedTestPdf(t, pdfPath)
+
+	details, info, err := ConvertPdfToPngWithDocumentInfo(pdfPath, dir, "annotated-", &Options{ExtractAnnotations: true})
+	if err != nil {
+		t.Fatalf("ConvertPdfToPngWithDocumentInfo failed: %v", err)
+	}
+
+	annotations := details[0].Annotations
+	if len(annotations) != 2 {
+		t.Fatalf("Expected 2 annotations, got %d", len(annotations))
+	}
+
+	square := annotations[0]
+	if square.Type != "Square" || square.Contents != "Check total" || square.Rendered {
+		t.Errorf("Unexpected square annotation: %+v", square)
+	}
+
+	// 100pt from the left, 92pt from the top, 100pt wide at 300 DPI
+	if square.X != 417 || square.Y != 383 || square.Width != 417 || square.Height != 417 {
+		t.Errorf("Expected square at 417,383 417x417, got %d,%d %dx%d", square.X, square.Y, square.Width, square.Height)
+	}
+
+	widget := annotations[1]
+	if widget.Type != "Widget" || widget.FieldName != "applicant" || widget.FieldType != "Tx" || widget.FieldValue != "Jane Doe" {
+		t.Errorf("Unexpected widget annotation: %+v", widget)
+	}
+
+	if len(info.FormFields) != 1 {
+		t.Fatalf("Expected 1 form field, got %d", len(info.FormFields))
+	}
+	if field := info.FormFields[0]; field.Name != "applicant" || field.Value != "Jane Doe" || field.Page != 1 {
+		t.Errorf("Unexpected form field: %+v", field)
+	}
+}
+
+func TestRenderPdfAnnotationsSelectively(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "annotated.pdf")
+	writeAnnotatedTestPdf(t, pdfPath)
+
+	// Pixel centres of the square annotation and the form field at 300 DPI
+	squareCentre := [2]int{625, 592}
+	widgetCentre := [2]int{833, 1615}
+
+	for _, tc := range []struct {
+		name        string
+		opts        *Options
+		squareInked bool
+		widgetInked bool
+	}{
+		{name: "none", opts: nil},
+		{name: "annotations", opts: &Options{RenderAnnotations: true}, squareInked: true},
+		{name: "formfields", opts: &Options{RenderFormFields: true}, widgetInked: true},
+	} {
+		details, err := ConvertPdfToPngWithOptions(pdfPath, dir, tc.name+"-", tc.opts)
+		if err != nil {
+			t.Fatalf("%s: conversion failed: %v", tc.name, err)
+		}
+
+		img, err := loadPng(details[0].URL)
+		if err != nil {
+			t.Fatalf("%s: failed to load page: %v", tc.name, err)
+		}
+
+		inked := func(p [2]int) bool {
+			r, _, _, _ := img.At(p[0], p[1]).RGBA()
+			return r < 0x8000
+		}
+		if got := inked(squareCentre); got != tc.squareInked {
+			t.Errorf("%s: square annotation rendered = %v, want %v", tc.name, got, tc.squareInked)
+		}
+		if got := inked(widgetCentre); got != tc.widgetInked {
+			t.Errorf("%s: form field rendered = %v, want %v", tc.name, got, tc.widgetInked)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dhushon/go-tifpdf2png"
+)
+
+// batchInput is an input file of a batch run and the output subdirectory it is converted into
+type batchInput struct {
+	path   string
+	subdir string
+}
+
+// batchResult is the JSON entry of one input in batch mode, holding its pages or error
+type batchResult struct {
+	Document *tifpdf2png.DocumentInfo  `json:"document,omitempty"`
+	Pages    []*tifpdf2png.ImageDetail `json:"pages,omitempty"`
+	Error    string                    `json:"error,omitempty"`
+	err      error                     // Error that stopped the conversion
+}
+
+// isBatch reports whether the arguments name more than a single input file
+func isBatch(args []string, recursive bool) bool {
+	if len(args) > 1 || recursive {
+		return true
+	}
+	if hasGlobMeta(args[0]) {
+		return true
+	}
+	info, err := os.Stat(args[0])
+	return err == nil && info.IsDir()
+}
+
+// hasGlobMeta reports whether a path contains glob pattern characters
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// collectInputs expands glob patterns and, when recursive is set, walks directories for
+// supported files. Each input is given a unique output subdirectory named after the file,
+// prefixed by its directory relative to the walked root.
+func collectInputs(args []string, recursive bool) ([]batchInput, error) {
+	var inputs []batchInput
+	seen := make(map[string]bool)
+	subdirs := make(map[string]bool)
+
+	add := func(path string, rel string) {
+		clean := filepath.Clean(path)
+		if seen[clean] {
+			return
+		}
+		seen[clean] = true
+
+		subdir := strings.TrimSuffix(rel, filepath.Ext(rel))
+		for n := 2; subdirs[subdir]; n++ {
+			subdir = strings.TrimSuffix(rel, filepath.Ext(rel)) + "-" + strconv.Itoa(n)
+		}
+		subdirs[subdir] = true
+		inputs = append(inputs, batchInput{path: path, subdir: subdir})
+	}
+
+	for _, arg := range args {
+		if arg == "-" {
+			return nil, fmt.Errorf("stdin (-) can only be converted on its own")
+		}
+		if isS3URL(arg) {
+			if _, key, err := tifpdf2png.ParseS3URL(arg); err != nil {
+				return nil, err
+			} else if key == "" {
+				return nil, fmt.Errorf("'%s' has no object key", arg)
+			}
+			add(arg, path.Base(arg))
+			continue
+		}
+		paths := []string{arg}
+		if hasGlobMeta(arg) {
+			matches, err := filepath.Glob(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern '%s': %w", arg, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no files match '%s'", arg)
+			}
+			paths = matches
+		}
+
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(path, filepath.Base(path))
+				continue
+			}
+			if !recursive {
+				return nil, fmt.Errorf("'%s' is a directory; use -r to convert the files in it", path)
+			}
+
+			root := path
+			err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() || !tifpdf2png.IsSupported(path) {
+					return nil
+				}
+				rel, err := filepath.Rel(root, path)
+				if err != nil {
+					return err
+				}
+				add(path, rel)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no supported files found")
+	}
+	return inputs, nil
+}
+
+// runBatch converts the inputs into their subdirectories of outputDir with up to
+// concurrency conversions at a time, writes a JSON object mapping each input to its
+// result, unless NDJSON lines were written instead, and returns the number of failed inputs
+func runBatch(inputs []batchInput, outputDir string, cfg *runConfig, concurrency int) int {
+	results := make(map[string]*batchResult, len(inputs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	work := make(chan batchInput)
+	for range min(concurrency, len(inputs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for input := range work {
+				result := convertBatchInput(input, outputDir, cfg)
+				mu.Lock()
+				results[input.path] = result
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, input := range inputs {
+		work <- input
+	}
+	close(work)
+	wg.Wait()
+
+	failed, pages := 0, 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+		pages += len(result.Pages)
+	}
+
+	if !cfg.ndjson {
+		if err := writeResult(results, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error marshaling ImageDetails to JSON: %v\n", err)
+			return len(inputs)
+		}
+	}
+
+	if !cfg.quiet {
+		fmt.Fprintf(os.Stderr, "\n✓ Converted %d of %d file(s), %d page(s)\n", len(inputs)-failed, len(inputs), pages)
+		printOutputLocation(outputDir, cfg)
+	}
+	return failed
+}
+
+// convertBatchInput converts one input of a batch run and reports its outcome
+func convertBatchInput(input batchInput, outputDir string, cfg *runConfig) *batchResult {
+	result := convertBatchInputPages(input, outputDir, cfg)
+	reportBatchResult(input, result, cfg)
+	return result
+}
+
+// reportBatchResult reports the outcome of an input on stderr and, with --ndjson, on stdout
+func reportBatchResult(input batchInput, result *batchResult, cfg *runConfig) {
+	if result.Error != "" {
+		fmt.Fprintf(os.Stderr, "✗ %s: %s\n", input.path, result.Error)
+	} else if !cfg.quiet {
+		fmt.Fprintf(os.Stderr, "✓ %s: %d page(s)\n", input.path, len(result.Pages))
+	}
+
+	if cfg.ndjson {
+		record := ndjsonRecord{Input: input.path, Done: true, PageCount: len(result.Pages), Document: result.Document, Error: result.Error}
+		if err := writeLine(record); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing NDJSON: %v\n", err)
+		}
+	}
+}
+
+// convertBatchInputPages converts one input of a batch run into its subdirectory
+func convertBatchInputPages(input batchInput, outputDir string, cfg *runConfig) *batchResult {
+	dir := filepath.Join(outputDir, input.subdir)
+	if !cfg.remoteOutput() {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return &batchResult{Error: err.Error(), err: err}
+		}
+	}
+
+	var onPage tifpdf2png.PageHandler
+	if cfg.ndjson {
+		onPage = func(detail *tifpdf2png.ImageDetail) error {
+			return writeLine(ndjsonRecord{Input: input.path, Page: detail})
+		}
+	}
+	result, err := convertInput(input.path, dir, cfg, onPage)
+	if err != nil {
+		return &batchResult{Error: err.Error(), err: err}
+	}
+	return &batchResult{Document: result.Document, Pages: result.Pages}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// States of an input in the job state file
+const (
+	jobPending = "pending"
+	jobDone    = "done"
+	jobFailed  = "failed"
+)
+
+// jobConfig holds the settings of a batch run with a state file
+type jobConfig struct {
+	stateFile   string
+	retries     int           // Retries of an input after a transient failure
+	backoff     time.Duration // Wait before the first retry, doubled for each further retry
+	retryFailed bool          // Convert inputs that failed in an earlier run again
+	concurrency int
+}
+
+// jobRecord is a line of the state file. The last line of an input holds its state.
+type jobRecord struct {
+	Input    string    `json:"input"`
+	State    string    `json:"state"`
+	Subdir   string    `json:"subdir,omitempty"`
+	Attempts int       `json:"attempts,omitempty"`
+	Pages    int       `json:"pages,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// jobReport is the JSON output of a batch run with a state file
+type jobReport struct {
+	Total       int          `json:"total"`
+	Done        int          `json:"done"`
+	Failed      int          `json:"failed"`
+	Pending     int          `json:"pending"`
+	Converted   int          `json:"converted"` // Inputs converted in this run
+	Pages       int          `json:"pages"`     // Pages converted in this run
+	Interrupted bool         `json:"interrupted,omitempty"`
+	Failures    []jobFailure `json:"failures,omitempty"`
+}
+
+// jobFailure is a failed input of a jobReport
+type jobFailure struct {
+	Input    string `json:"input"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+}
+
+// jobStore appends the state changes of inputs to a JSON lines file
+type jobStore struct {
+	mu     sync.Mutex
+	file   *os.File
+	states map[string]*jobRecord
+}
+
+// openJobStore loads the last state of each input from a state file, ignoring a
+// partially written last line, and opens it for appending
+func openJobStore(path string) (*jobStore, error) {
+	store := &jobStore{states: make(map[string]*jobRecord)}
+	file, err := os.Open(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var record jobRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record.Input == "" {
+				continue
+			}
+			store.states[record.Input] = &record
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	store.file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// state returns the last recorded state of an input, or nil if it has none
+func (s *jobStore) state(input string) *jobRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.states[input]
+}
+
+// record appends a state change of an input to the state file
+func (s *jobStore) record(record jobRecord) error {
+	record.Time = time.Now().UTC()
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[record.Input] = &record
+	_, err = s.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the state file
+func (s *jobStore) Close() error {
+	return s.file.Close()
+}
+
+// runJobs converts the inputs like runBatch, recording the state of each input in the
+// state file so that a rerun skips the inputs already done. Transient failures are
+// retried with exponential backoff. On SIGINT or SIGTERM no new inputs are started. It
+// writes a jobReport and returns the number of inputs failed or not yet done.
+func runJobs(inputs []batchInput, outputDir string, cfg *runConfig, jc *jobConfig) int {
+	store, err := openJobStore(jc.stateFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening state file: %v\n", err)
+		return len(inputs)
+	}
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var mu sync.Mutex
+	report := &jobReport{Total: len(inputs)}
+	var wg sync.WaitGroup
+	work := make(chan batchInput)
+	for range min(jc.concurrency, len(inputs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for input := range work {
+				result := runJob(ctx, input, outputDir, cfg, jc, store)
+				if result == nil {
+					continue
+				}
+				reportBatchResult(input, result, cfg)
+				if result.Error == "" {
+					mu.Lock()
+					report.Converted++
+					report.Pages += len(result.Pages)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, input := range inputs {
+		if state := store.state(input.path); state != nil && (state.State == jobDone || state.State == jobFailed && !jc.retryFailed) {
+			continue
+		}
+		select {
+		case work <- input:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(work)
+	wg.Wait()
+	report.Interrupted = ctx.Err() != nil
+
+	for _, input := range inputs {
+		state := store.state(input.path)
+		switch {
+		case state != nil && state.State == jobDone:
+			report.Done++
+		case state != nil && state.State == jobFailed:
+			report.Failed++
+			report.Failures = append(report.Failures, jobFailure{Input: input.path, Error: state.Error, Attempts: state.Attempts})
+		default:
+			report.Pending++
+		}
+	}
+
+	if err := writeResult(report, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling report to JSON: %v\n", err)
+	}
+	if !cfg.quiet {
+		fmt.Fprintf(os.Stderr, "\n✓ Converted %d file(s), %d page(s) in this run; %d of %d done, %d failed, %d pending\n",
+			report.Converted, report.Pages, report.Done, report.Total, report.Failed, report.Pending)
+		printOutputLocation(outputDir, cfg)
+	}
+	return report.Failed + report.Pending
+}
+
+// runJob converts an input, retrying transient failures, and records its state. It
+// returns nil if the run was interrupted before the input was done.
+func runJob(ctx context.Context, input batchInput, outputDir string, cfg *runConfig, jc *jobConfig, store *jobStore) *batchResult {
+	record := func(r jobRecord) {
+		if err := store.record(r); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing state file: %v\n", err)
+		}
+	}
+
+	attempts := 0
+	if state := store.state(input.path); state != nil {
+		attempts = state.Attempts
+	}
+	backoff := jc.backoff
+	for retry := 0; ; retry++ {
+		attempts++
+		record(jobRecord{Input: input.path, State: jobPending, Subdir: input.subdir, Attempts: attempts})
+
+		result := convertBatchInputPages(input, outputDir, cfg)
+		if result.err == nil {
+			record(jobRecord{Input: input.path, State: jobDone, Subdir: input.subdir, Attempts: attempts, Pages: len(result.Pages)})
+			return result
+		}
+		if retry >= jc.retries || !isTransient(result.err) {
+			record(jobRecord{Input: input.path, State: jobFailed, Subdir: input.subdir, Attempts: attempts, Error: result.Error})
+			return result
+		}
+
+		fmt.Fprintf(os.Stderr, "↻ %s: %s; retrying in %s\n", input.path, result.Error, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil
+		}
+		backoff *= 2
+	}
+}
+
+// isTransient reports whether a conversion error may not recur on a retry: timeouts,
+// network failures and object store throttling or server errors. Any other error, such
+// as a corrupt, unsupported, missing or encrypted input, is permanent.
+func isTransient(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var s3Err minio.ErrorResponse
+	if errors.As(err, &s3Err) {
+		return s3Err.StatusCode >= 500 || s3Err.StatusCode == http.StatusTooManyRequests ||
+			s3Err.Code == "SlowDown" || s3Err.Code == "RequestTimeout"
+	}
+	return false
+}
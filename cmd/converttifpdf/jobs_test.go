@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dhushon/go-tifpdf2png"
+	"github.com/minio/minio-go/v7"
+)
+
+func TestJobStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.jsonl")
+	store, err := openJobStore(path)
+	if err != nil {
+		t.Fatalf("openJobStore failed: %v", err)
+	}
+	for _, record := range []jobRecord{
+		{Input: "a.pdf", State: jobPending, Attempts: 1},
+		{Input: "a.pdf", State: jobDone, Attempts: 1, Pages: 3},
+		{Input: "b.pdf", State: jobPending, Attempts: 1},
+		{Input: "b.pdf", State: jobFailed, Attempts: 1, Error: "broken"},
+		{Input: "c.pdf", State: jobPending, Attempts: 2},
+	} {
+		if err := store.record(record); err != nil {
+			t.Fatalf("record failed: %v", err)
+		}
+	}
+	store.Close()
+
+	// A line cut short by a crash is ignored on resume
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open state file: %v", err)
+	}
+	f.WriteString(`{"input":"c.pdf","state":"do`)
+	f.Close()
+
+	store, err = openJobStore(path)
+	if err != nil {
+		t.Fatalf("openJobStore failed on resume: %v", err)
+	}
+	defer store.Close()
+	if state := store.state("a.pdf"); state == nil || state.State != jobDone || state.Pages != 3 {
+		t.Errorf("Expected a.pdf done with 3 pages, got %+v", state)
+	}
+	if state := store.state("b.pdf"); state == nil || state.State != jobFailed || state.Error != "broken" {
+		t.Errorf("Expected b.pdf failed, got %+v", state)
+	}
+	if state := store.state("c.pdf"); state == nil || state.State != jobPending || state.Attempts != 2 {
+		t.Errorf("Expected c.pdf pending after 2 attempts, got %+v", state)
+	}
+	if store.state("d.pdf") != nil {
+		t.Error("Expected no state for an unknown input")
+	}
+}
+
+// timeoutError is a net.Error reporting a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"corrupt document", errors.New("cannot find startxref"), false},
+		{"unsupported", tifpdf2png.ErrUnsupportedFormat, false},
+		{"encrypted", tifpdf2png.ErrEncrypted, false},
+		{"missing", fmt.Errorf("open a.pdf: %w", fs.ErrNotExist), false},
+		{"deadline", fmt.Errorf("convert: %w", context.DeadlineExceeded), true},
+		{"timeout", timeoutError{}, true},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{"S3 unavailable", fmt.Errorf("open s3://b/a.pdf: %w", minio.ErrorResponse{Code: "ServiceUnavailable", StatusCode: 503}), true},
+		{"S3 throttled", minio.ErrorResponse{Code: "SlowDown", StatusCode: 503}, true},
+		{"S3 missing key", minio.ErrorResponse{Code: "NoSuchKey", StatusCode: 404}, false},
+		{"S3 access denied", minio.ErrorResponse{Code: "AccessDenied", StatusCode: 403}, false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRunJobCorruptInput(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "broken.pdf")
+	if err := os.WriteFile(path, []byte("not a pdf"), 0644); err != nil {
+		t.Fatalf("Failed to write broken PDF: %v", err)
+	}
+	store, err := openJobStore(filepath.Join(dir, "state.jsonl"))
+	if err != nil {
+		t.Fatalf("openJobStore failed: %v", err)
+	}
+	defer store.Close()
+
+	// A retry would wait for the backoff until the context expires and return nil
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cfg := &runConfig{opts: &tifpdf2png.Options{}, quiet: true}
+	jc := &jobConfig{retries: 3, backoff: time.Hour}
+	result := runJob(ctx, batchInput{path: path, subdir: "broken"}, filepath.Join(dir, "out"), cfg, jc, store)
+	if result == nil || result.Error == "" {
+		t.Fatalf("Expected the corrupt input to fail, got %+v", result)
+	}
+	if state := store.state(path); state == nil || state.State != jobFailed || state.Attempts != 1 {
+		t.Errorf("Expected the corrupt input failed after 1 attempt, got %+v", state)
+	}
+}
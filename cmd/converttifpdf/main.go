@@ -1,12 +1,20 @@
-// Package main provides a CLI tool for converting TIFF and PDF files to PNG images.
+// Package main provides a CLI tool for converting TIFF, PDF, e-book and raster image files to PNG images.
 package main
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dhushon/go-tifpdf2png"
 )
@@ -17,77 +25,524 @@ var (
 	date    = "unknown"
 )
 
+// supportedFormats lists the input file extensions for the usage text
+const supportedFormats = ".tif, .tiff, .pdf, .xps, .oxps, .epub, .cbz, .fb2, .mobi, .jpg, .jpeg, .png, .bmp, .gif, .webp, .jb2, .jbig2"
+
+// conversionOutput is the JSON document written when document info is requested
+type conversionOutput struct {
+	Document *tifpdf2png.DocumentInfo  `json:"document,omitempty"`
+	Pages    []*tifpdf2png.ImageDetail `json:"pages"`
+	Montage  string                    `json:"-"`
+}
+
+// ndjsonRecord is a line of --ndjson output other than a bare page: the document info of
+// an input, or in batch mode a page of an input or the outcome of the whole input
+type ndjsonRecord struct {
+	Input     string                   `json:"input,omitempty"`
+	Page      *tifpdf2png.ImageDetail  `json:"page,omitempty"`
+	Done      bool                     `json:"done,omitempty"`
+	PageCount int                      `json:"page_count,omitempty"`
+	Document  *tifpdf2png.DocumentInfo `json:"document,omitempty"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [options] <input-file>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [options] [-r] <input-file|dir|pattern>...\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [options] --archive tar|zip - < input > pages.tar\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s watch [options] <dir>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s serve [options]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s --version\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\nConverts a TIFF, PDF, XPS, e-book or raster image file to PNG images in the output directory\n")
+	fmt.Fprintf(os.Stderr, "and outputs ImageDetails to stdout as JSON.\n")
+	fmt.Fprintf(os.Stderr, "\nWith several inputs, glob patterns or -r, each input is converted into its own subdirectory\n")
+	fmt.Fprintf(os.Stderr, "of the output directory and the JSON output maps each input to its pages or error.\n")
+	fmt.Fprintf(os.Stderr, "With --state, the progress of each input is recorded so that a rerun resumes where the\n")
+	fmt.Fprintf(os.Stderr, "last one stopped, and the JSON output is a report of the whole run.\n")
+	fmt.Fprintf(os.Stderr, "\nAn input of - reads the document from stdin and detects its format from its content.\n")
+	fmt.Fprintf(os.Stderr, "With --archive, pages and the JSON output (as manifest.json) are written to stdout as a\n")
+	fmt.Fprintf(os.Stderr, "tar or zip stream instead of files.\n")
+	fmt.Fprintf(os.Stderr, "\nwatch converts documents dropped into <dir> once they stop changing, into subdirectories\n")
+	fmt.Fprintf(os.Stderr, "of the output directory (default <dir>/output), and moves each to <dir>/done or <dir>/failed\n")
+	fmt.Fprintf(os.Stderr, "with a JSON sidecar of its pages or error. It stops on SIGINT or SIGTERM.\n")
+	fmt.Fprintf(os.Stderr, "\nOptions:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(os.Stderr, "\nSupported formats: %s\n", supportedFormats)
+}
+
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <input-file>\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "       %s --version\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "\nConverts a TIFF or PDF file to PNG images in the current working directory\n")
-		fmt.Fprintf(os.Stderr, "and outputs ImageDetails to stdout as JSON.\n")
-		fmt.Fprintf(os.Stderr, "\nSupported formats: .tif, .tiff, .pdf\n")
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		os.Exit(runServe(os.Args[2:]))
+	}
+	watchMode := len(os.Args) > 1 && os.Args[1] == "watch"
+
+	var showVersion bool
+	flag.BoolVar(&showVersion, "version", false, "print version information and exit")
+	flag.BoolVar(&showVersion, "v", false, "shorthand for --version")
+	var outputDir string
+	flag.StringVar(&outputDir, "output-dir", "", "write output files to `dir` or below an s3://bucket/prefix URL (default the current working directory)")
+	flag.StringVar(&outputDir, "o", "", "shorthand for --output-dir")
+	prefixFlag := flag.String("prefix", "", "output filename `prefix` (default <base>-page-)")
+	format := flag.String("format", "png", "output format for PDF pages: png, svg or html")
+	dpi := flag.Float64("dpi", 0, "render PDF and document pages at `dpi` (default 300)")
+	pages := flag.String("pages", "", "convert only the selected `pages`, such as 1-3,5,8- (default all)")
+	noCrop := flag.Bool("no-crop", false, "keep whole pages instead of cropping them to their content")
+	noBinarize := flag.Bool("no-binarize", false, "keep page colours instead of converting pages to black on white")
+	threshold := flag.Int("threshold", 128, "binarize pixels with a luminance below `level` (1-255) to black")
+	quiet := flag.Bool("quiet", false, "suppress progress and log messages on stderr")
+	jsonCompact := flag.Bool("json-compact", false, "write the JSON output on a single line")
+	archive := flag.String("archive", "", "write pages and the JSON output as manifest.json to stdout as a `tar` or zip stream")
+	ndjson := flag.Bool("ndjson", false, "write one JSON line per page as soon as it is saved instead of a single JSON document")
+	montage := flag.Bool("montage", false, "also write a contact sheet of all pages to <base>-montage.png")
+	extractText := flag.Bool("text", false, "include the PDF text layer and word boxes in the JSON output")
+	includeHTML := flag.Bool("html", false, "with --text, also include MuPDF's positioned HTML for each page")
+	extractLinks := flag.Bool("links", false, "list the URIs of PDF page links in the JSON output")
+	extractImages := flag.Bool("extract-images", false, "save PDF pages that are a single scanned image at the image's native resolution")
+	withInfo := flag.Bool("info", false, "output an object with document metadata, outline and page labels alongside the pages")
+	extractAnnotations := flag.Bool("annotations", false, "list PDF annotations and form fields in the JSON output")
+	renderAnnotations := flag.Bool("render-annotations", false, "draw PDF markup annotations onto the page images")
+	renderFormFields := flag.Bool("render-forms", false, "draw PDF form fields and their values onto the page images")
+	renderSignatures := flag.Bool("render-signatures", false, "draw PDF signature appearances onto the page images")
+	squarePixels := flag.Bool("square-pixels", false, "resample TIFF pages with non-square pixels, such as 204x98 DPI faxes, to square pixels")
+	targetDPI := flag.Float64("target-dpi", 0, "resample TIFF pages to square pixels at `dpi`")
+	subImages := flag.String("sub-images", "first", "TIFF sub-images to convert: first, all or largest")
+	worldFile := flag.Bool("world-file", false, "write a world file (.pgw) next to each PNG converted from a GeoTIFF")
+	writeManifest := flag.Bool("manifest", false, "write a manifest.json describing the source, options and pages next to the pages of each input")
+	passwordFile := flag.String("password-file", "", "read the password for encrypted PDFs from `file`")
+	recursive := flag.Bool("r", false, "convert the supported files in directory inputs and their subdirectories")
+	concurrency := flag.Int("concurrency", runtime.NumCPU(), "convert up to `n` inputs at a time in batch and watch mode")
+	s3Endpoint := flag.String("s3-endpoint", "", "object store `host[:port]` of s3:// URLs, with http:// to disable TLS (default $AWS_ENDPOINT_URL or s3.amazonaws.com)")
+	s3Region := flag.String("s3-region", "", "`region` of the s3:// buckets (default $AWS_REGION)")
+	s3PathStyle := flag.Bool("s3-path-style", false, "address s3:// buckets in the URL path, as MinIO and other S3-compatible stores expect")
+	stateFile := flag.String("state", "", "record the state of each input in `file` and skip the inputs already done on a rerun")
+	retries := flag.Int("retries", 3, "with --state, retry inputs failing with transient errors up to `n` times")
+	backoff := flag.Duration("backoff", time.Second, "with --state, wait `duration` before the first retry, doubling it for each further retry")
+	retryFailed := flag.Bool("retry-failed", false, "with --state, convert the inputs that failed in an earlier run again")
+	interval := flag.Duration("interval", time.Second, "in watch mode, scan the folder every `duration`")
+	settle := flag.Duration("settle", 2*time.Second, "in watch mode, convert files once unchanged for `duration`")
+	flag.Usage = usage
+	args := os.Args[1:]
+	if watchMode {
+		args = os.Args[2:]
+	}
+	if err := flag.CommandLine.Parse(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
 	// Handle version flag
-	if os.Args[1] == "--version" || os.Args[1] == "-v" {
+	if showVersion {
 		fmt.Printf("go-tifpdf2png convert %s\n", version)
 		fmt.Printf("  commit: %s\n", commit)
 		fmt.Printf("  built:  %s\n", date)
 		os.Exit(0)
 	}
 
-	inputFile := os.Args[1]
+	if flag.NArg() < 1 {
+		usage()
+		os.Exit(1)
+	}
+
+	if *quiet {
+		slog.SetLogLoggerLevel(slog.LevelError)
+	}
+	if *threshold < 1 || *threshold > 255 {
+		fmt.Fprintf(os.Stderr, "Error: --threshold must be between 1 and 255\n")
+		os.Exit(1)
+	}
+
+	var archiveSink *tifpdf2png.ArchiveSink
+	switch strings.ToLower(*archive) {
+	case "":
+	case "tar":
+		archiveSink = tifpdf2png.NewTarSink(os.Stdout)
+	case "zip":
+		archiveSink = tifpdf2png.NewZipSink(os.Stdout)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: --archive must be tar or zip\n")
+		os.Exit(1)
+	}
+	if archiveSink != nil && (outputDir != "" || *montage || *ndjson || *writeManifest || watchMode || *stateFile != "") {
+		fmt.Fprintf(os.Stderr, "Error: --archive can't be combined with --output-dir, --montage, --ndjson, --manifest, --state or watch\n")
+		os.Exit(1)
+	}
+	s3Output := ""
+	if isS3URL(outputDir) {
+		if *montage || watchMode {
+			fmt.Fprintf(os.Stderr, "Error: an s3:// output can't be combined with --montage or watch\n")
+			os.Exit(1)
+		}
+		if _, _, err := tifpdf2png.ParseS3URL(outputDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		s3Output, outputDir = outputDir, ""
+	}
+	if *retries < 0 || *backoff < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --retries and --backoff can't be negative\n")
+		os.Exit(1)
+	}
+	if watchMode {
+		if info, err := os.Stat(flag.Arg(0)); flag.NArg() != 1 || err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "Error: watch takes a single existing directory\n")
+			os.Exit(1)
+		}
+		if *concurrency < 1 || *interval <= 0 || *settle < 0 {
+			fmt.Fprintf(os.Stderr, "Error: --concurrency and --interval must be positive and --settle can't be negative\n")
+			os.Exit(1)
+		}
+		if outputDir == "" {
+			outputDir = filepath.Join(flag.Arg(0), "output")
+		}
+	}
+
+	// Write to the output directory, or the current working directory by default
+	var err error
+	if archiveSink != nil || s3Output != "" {
+		// Pages are only written to the archive or object store
+	} else if outputDir == "" {
+		outputDir, err = os.Getwd()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+			os.Exit(1)
+		}
+	} else if err := os.MkdirAll(outputDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := &tifpdf2png.Options{
+		Format: tifpdf2png.OutputFormat(strings.ToLower(*format)),
+
+		DPI:   *dpi,
+		Pages: tifpdf2png.PageSelection(*pages),
+
+		NoCrop:     *noCrop,
+		NoBinarize: *noBinarize,
+		Threshold:  uint8(*threshold),
+
+		ExtractText: *extractText,
+		IncludeHTML: *includeHTML,
+
+		ExtractLinks:         *extractLinks,
+		ExtractFullPageImage: *extractImages,
+
+		ExtractAnnotations: *extractAnnotations,
+		RenderAnnotations:  *renderAnnotations,
+		RenderFormFields:   *renderFormFields,
+		RenderSignatures:   *renderSignatures,
+
+		TargetDPI:    *targetDPI,
+		SquarePixels: *squarePixels,
+		SubImages:    tifpdf2png.SubImageMode(strings.ToLower(*subImages)),
+
+		WriteWorldFile: *worldFile,
+		WriteManifest:  *writeManifest,
+	}
+	if *passwordFile != "" {
+		password, err := os.ReadFile(*passwordFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading password file: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Password = strings.TrimRight(string(password), "\r\n")
+	}
+
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *montage && opts.Format != tifpdf2png.FormatPNG {
+		fmt.Fprintf(os.Stderr, "Error: --montage requires --format png\n")
+		os.Exit(1)
+	}
+
+	cfg := &runConfig{
+		opts:        opts,
+		prefix:      *prefixFlag,
+		withInfo:    *withInfo,
+		montage:     *montage,
+		quiet:       *quiet,
+		jsonCompact: *jsonCompact,
+		ndjson:      *ndjson,
+		archive:     archiveSink,
+		s3:          s3Config(*s3Endpoint, *s3Region, *s3PathStyle),
+		s3Output:    s3Output,
+	}
+
+	if watchMode {
+		wc := &watchConfig{dir: flag.Arg(0), outputDir: outputDir, interval: *interval, settle: *settle, concurrency: *concurrency}
+		if err := runWatch(wc, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if isBatch(flag.Args(), *recursive) || *stateFile != "" {
+		if *concurrency < 1 {
+			fmt.Fprintf(os.Stderr, "Error: --concurrency must be at least 1\n")
+			os.Exit(1)
+		}
+		inputs, err := collectInputs(flag.Args(), *recursive)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		failed := 0
+		if *stateFile != "" {
+			jc := &jobConfig{stateFile: *stateFile, retries: *retries, backoff: *backoff, retryFailed: *retryFailed, concurrency: *concurrency}
+			failed = runJobs(inputs, outputDir, cfg, jc)
+		} else {
+			failed = runBatch(inputs, outputDir, cfg, *concurrency)
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	inputFile := flag.Arg(0)
 
 	// Check if file exists
-	if _, err := os.Stat(inputFile); os.IsNotExist(err) {
+	if _, err := os.Stat(inputFile); inputFile != "-" && !isS3URL(inputFile) && os.IsNotExist(err) {
 		fmt.Fprintf(os.Stderr, "Error: File '%s' does not exist\n", inputFile)
 		os.Exit(1)
 	}
 
-	// Get current working directory
-	cwd, err := os.Getwd()
+	var onPage tifpdf2png.PageHandler
+	if cfg.ndjson {
+		onPage = func(detail *tifpdf2png.ImageDetail) error {
+			return writeLine(detail)
+		}
+	}
+	result, err := convertInput(inputFile, outputDir, cfg, onPage)
+	if errors.Is(err, tifpdf2png.ErrUnsupportedFormat) && (inputFile == "-" || isS3URL(inputFile)) {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported content in %s\n", inputName(inputFile))
+		fmt.Fprintf(os.Stderr, "Supported formats: %s\n", supportedFormats)
+		os.Exit(1)
+	}
+	if errors.Is(err, tifpdf2png.ErrUnsupportedFormat) {
+		fmt.Fprintf(os.Stderr, "Error: Unsupported file type '%s'\n", filepath.Ext(inputFile))
+		fmt.Fprintf(os.Stderr, "Supported formats: %s\n", supportedFormats)
+		os.Exit(1)
+	}
+	if errors.Is(err, tifpdf2png.ErrEncrypted) {
+		fmt.Fprintf(os.Stderr, "Error: '%s' is encrypted; supply its password with --password-file\n", inputFile)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error converting %s to %s: %v\n", inputKind(inputFile), strings.ToUpper(string(opts.Format)), err)
+		os.Exit(1)
+	}
+
+	// Output ImageDetails as JSON to stdout, where NDJSON pages have already been written
+	var output any = result.Pages
+	if cfg.withInfo {
+		output = result
+	}
+	if cfg.ndjson {
+		err = nil
+		if cfg.withInfo {
+			err = writeLine(ndjsonRecord{Document: result.Document})
+		}
+	} else {
+		err = writeResult(output, cfg)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error getting current directory: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error marshaling ImageDetails to JSON: %v\n", err)
 		os.Exit(1)
 	}
 
+	// Print summary to stderr so it doesn't interfere with JSON output
+	if cfg.quiet {
+		return
+	}
+	if result.Montage != "" {
+		fmt.Fprintf(os.Stderr, "✓ Montage written to: %s\n", result.Montage)
+	}
+	outputFormat := "PNG"
+	if len(result.Pages) > 0 {
+		outputFormat = strings.ToUpper(result.Pages[0].Format)
+	}
+	fmt.Fprintf(os.Stderr, "\n✓ Converted %d page(s) from %s to %s\n", len(result.Pages), inputFile, outputFormat)
+	printOutputLocation(outputDir, cfg)
+}
+
+// runConfig holds the settings shared by every input of a run
+type runConfig struct {
+	opts        *tifpdf2png.Options
+	prefix      string // Output filename prefix; empty means <base>-page-
+	withInfo    bool
+	montage     bool
+	quiet       bool
+	jsonCompact bool
+	ndjson      bool                    // Write pages as NDJSON lines as they are saved
+	archive     *tifpdf2png.ArchiveSink // Receives all output files in archive mode
+	s3          *tifpdf2png.S3Config    // Object store of s3:// inputs and output
+	s3Output    string                  // s3://bucket/prefix URL output files are written below, if any
+}
+
+// remoteOutput reports whether output files are written somewhere other than the output directory
+func (cfg *runConfig) remoteOutput() bool {
+	return cfg.archive != nil || cfg.s3Output != ""
+}
+
+// convertInput converts one input file, stdin for "-" or an s3:// object into dir,
+// passing each saved page to onPage if it is set, and writes a montage when requested.
+// In archive and s3:// output mode dir is the directory of the pages below the archive
+// root or output prefix.
+func convertInput(inputFile string, dir string, cfg *runConfig, onPage tifpdf2png.PageHandler) (*conversionOutput, error) {
 	// Extract base filename without extension for prefix
 	baseName := filepath.Base(inputFile)
-	ext := filepath.Ext(baseName)
-	prefix := baseName[:len(baseName)-len(ext)] + "-page-"
+	stem := strings.TrimSuffix(baseName, filepath.Ext(baseName))
+	if inputFile == "-" {
+		stem = "stdin"
+	}
+	prefix := cfg.prefix
+	if prefix == "" {
+		prefix = stem + "-page-"
+	}
 
-	// Detect file type and route to appropriate converter
-	extLower := strings.ToLower(ext)
-	var imageDetails []*tifpdf2png.ImageDetail
+	// Conversions of a batch run concurrently, so each gets its own copy of the options
+	opts := *cfg.opts
+	opts.OnPage = onPage
+	switch {
+	case cfg.archive != nil:
+		opts.Sink = archiveDirSink{archive: cfg.archive, dir: dir}
+	case cfg.s3Output != "":
+		bucket, keyPrefix, err := tifpdf2png.ParseS3URL(cfg.s3Output)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Sink, err = tifpdf2png.NewS3Sink(cfg.s3, bucket, path.Join(keyPrefix, filepath.ToSlash(dir))); err != nil {
+			return nil, err
+		}
+	}
 
-	switch extLower {
-	case ".pdf":
-		imageDetails, err = tifpdf2png.ConvertPdfToPngWithImageDetails(inputFile, cwd, prefix)
+	// Documents from stdin and object stores are read into memory
+	var reader io.Reader
+	if inputFile == "-" {
+		reader = os.Stdin
+	} else if isS3URL(inputFile) {
+		bucket, key, err := tifpdf2png.ParseS3URL(inputFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error converting PDF to PNG: %v\n", err)
-			os.Exit(1)
+			return nil, err
 		}
-	case ".tif", ".tiff":
-		imageDetails, err = tifpdf2png.ConvertTiffToPngWithImageDetails(inputFile, cwd, prefix)
+		obj, err := tifpdf2png.OpenS3Object(cfg.s3, bucket, key)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error converting TIFF to PNG: %v\n", err)
-			os.Exit(1)
+			return nil, err
+		}
+		defer obj.Close()
+		reader = obj
+	}
+
+	result := &conversionOutput{}
+	var err error
+	if reader != nil {
+		if cfg.withInfo {
+			result.Pages, result.Document, err = tifpdf2png.ConvertReaderWithDocumentInfo(reader, dir, prefix, &opts)
+		} else {
+			result.Pages, err = tifpdf2png.ConvertReaderWithOptions(reader, dir, prefix, &opts)
+		}
+	} else if cfg.withInfo {
+		result.Pages, result.Document, err = tifpdf2png.ConvertFileWithDocumentInfo(inputFile, dir, prefix, &opts)
+	} else {
+		result.Pages, err = tifpdf2png.ConvertFileWithOptions(inputFile, dir, prefix, &opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.montage {
+		montagePath := filepath.Join(dir, stem+"-montage.png")
+		if _, err := tifpdf2png.RenderMontage(result.Pages, montagePath, nil); err != nil {
+			return nil, fmt.Errorf("rendering montage: %w", err)
 		}
+		result.Montage = montagePath
+	}
+	return result, nil
+}
+
+// inputKind names the type of an input file for error messages
+func inputKind(inputFile string) string {
+	ext := strings.ToLower(filepath.Ext(inputFile))
+	switch {
+	case ext == ".tif" || ext == ".tiff":
+		return "TIFF"
+	case tifpdf2png.IsRasterImage(inputFile):
+		return "image"
 	default:
-		fmt.Fprintf(os.Stderr, "Error: Unsupported file type '%s'\n", ext)
-		fmt.Fprintf(os.Stderr, "Supported formats: .tif, .tiff, .pdf\n")
-		os.Exit(1)
+		return strings.ToUpper(strings.TrimPrefix(ext, "."))
+	}
+}
+
+// archiveDirSink names the members of an archive after a directory within the archive
+type archiveDirSink struct {
+	archive *tifpdf2png.ArchiveSink
+	dir     string
+}
+
+// Create implements tifpdf2png.PageSink
+func (s archiveDirSink) Create(name string) (io.WriteCloser, string, error) {
+	return s.archive.Create(path.Join(filepath.ToSlash(s.dir), name))
+}
+
+// writeResult writes the JSON output to stdout as indented or, if compact is set,
+// single-line JSON. In archive mode it is written as the manifest.json member, which
+// completes the archive.
+func writeResult(v any, cfg *runConfig) error {
+	var output []byte
+	var err error
+	if cfg.jsonCompact {
+		output, err = json.Marshal(v)
+	} else {
+		output, err = json.MarshalIndent(v, "", "  ")
+	}
+	if err != nil {
+		return err
 	}
 
-	// Output ImageDetails as JSON to stdout
-	output, err := json.MarshalIndent(imageDetails, "", "  ")
+	if cfg.archive == nil {
+		fmt.Println(string(output))
+		return nil
+	}
+	manifest, _, err := cfg.archive.Create("manifest.json")
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error marshaling ImageDetails to JSON: %v\n", err)
-		os.Exit(1)
+		return err
+	}
+	if _, err := manifest.Write(append(output, '\n')); err != nil {
+		return err
 	}
+	if err := manifest.Close(); err != nil {
+		return err
+	}
+	return cfg.archive.Close()
+}
 
-	fmt.Println(string(output))
+// printOutputLocation reports where the output files were written
+func printOutputLocation(outputDir string, cfg *runConfig) {
+	if cfg.archive != nil {
+		fmt.Fprintf(os.Stderr, "✓ Output files written to stdout as an archive with manifest.json\n")
+		return
+	}
+	if cfg.s3Output != "" {
+		outputDir = cfg.s3Output
+	}
+	fmt.Fprintf(os.Stderr, "✓ Output files in: %s\n", outputDir)
+}
 
-	// Print summary to stderr so it doesn't interfere with JSON output
-	fmt.Fprintf(os.Stderr, "\n✓ Converted %d page(s) from %s to PNG\n", len(imageDetails), inputFile)
-	fmt.Fprintf(os.Stderr, "✓ Output files in: %s\n", cwd)
+// stdoutMu keeps the NDJSON lines of concurrent conversions from interleaving
+var stdoutMu sync.Mutex
+
+// writeLine writes a value to stdout as a single JSON line
+func writeLine(v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	stdoutMu.Lock()
+	defer stdoutMu.Unlock()
+	_, err = fmt.Println(string(line))
+	return err
 }
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/dhushon/go-tifpdf2png"
+)
+
+// isS3URL reports whether an input or output directory is an s3:// URL
+func isS3URL(s string) bool {
+	return strings.HasPrefix(s, "s3://")
+}
+
+// s3Config returns the object store settings from the flags, falling back to the
+// AWS_ENDPOINT_URL_S3, AWS_ENDPOINT_URL and AWS_REGION environment variables, with the
+// credentials from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN. An
+// endpoint with an http:// scheme is accessed without TLS.
+func s3Config(endpoint string, region string, pathStyle bool) *tifpdf2png.S3Config {
+	for _, env := range []string{"AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"} {
+		if endpoint == "" {
+			endpoint = os.Getenv(env)
+		}
+	}
+	if endpoint == "" {
+		endpoint = "s3.amazonaws.com"
+	}
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+
+	cfg := &tifpdf2png.S3Config{
+		Region:       region,
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		PathStyle:    pathStyle,
+	}
+	if rest, ok := strings.CutPrefix(endpoint, "http://"); ok {
+		cfg.Insecure = true
+		endpoint = rest
+	}
+	cfg.Endpoint = strings.TrimSuffix(strings.TrimPrefix(endpoint, "https://"), "/")
+	return cfg
+}
+
+// inputName names an input for messages
+func inputName(inputFile string) string {
+	if inputFile == "-" {
+		return "stdin"
+	}
+	return inputFile
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dhushon/go-tifpdf2png"
+)
+
+// runServe runs the serve subcommand, serving conversions over HTTP until interrupted,
+// and returns the exit status
+func runServe(args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s serve [options]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nServes POST /convert, accepting a document as the request body or the file field of a\n")
+		fmt.Fprintf(os.Stderr, "multipart form, GET /pages/ for the converted pages and DELETE /pages/{id} to remove them.\n")
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		fs.PrintDefaults()
+	}
+	addr := fs.String("addr", ":8080", "listen on `address`")
+	var outputDir string
+	fs.StringVar(&outputDir, "output-dir", "", "save and serve pages from `dir` (default a tifpdf2png directory in the temp directory)")
+	fs.StringVar(&outputDir, "o", "", "shorthand for --output-dir")
+	baseURL := fs.String("base-url", "", "prefix page URLs with `url`, such as https://convert.example.com (default relative URLs)")
+	maxUpload := fs.Int64("max-upload-mb", 100, "reject uploads larger than `n` MiB")
+	timeout := fs.Duration("timeout", 5*time.Minute, "stop conversions taking longer than `duration`, checked between pages")
+	concurrency := fs.Int("concurrency", runtime.NumCPU(), "run up to `n` conversions at a time")
+	maxDPI := fs.Float64("max-dpi", 600, "reject requests for a dpi above `dpi`")
+	pageTTL := fs.Duration("page-ttl", time.Hour, "remove saved pages after `duration`, or keep them until deleted if 0")
+	passwordFile := fs.String("password-file", "", "read the password for encrypted PDFs from `file`")
+	quiet := fs.Bool("quiet", false, "suppress log messages other than errors on stderr")
+	fs.Parse(args)
+
+	if *quiet {
+		slog.SetLogLoggerLevel(slog.LevelError)
+	}
+	if *maxUpload < 1 || *concurrency < 1 {
+		fmt.Fprintf(os.Stderr, "Error: --max-upload-mb and --concurrency must be at least 1\n")
+		return 1
+	}
+	if *maxDPI <= 0 || *pageTTL < 0 {
+		fmt.Fprintf(os.Stderr, "Error: --max-dpi must be positive and --page-ttl can't be negative\n")
+		return 1
+	}
+	if *pageTTL == 0 {
+		// ServerOptions keeps pages until they are deleted when PageTTL is negative
+		*pageTTL = -1
+	}
+
+	opts := &tifpdf2png.Options{}
+	if *passwordFile != "" {
+		password, err := os.ReadFile(*passwordFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading password file: %v\n", err)
+			return 1
+		}
+		opts.Password = strings.TrimRight(string(password), "\r\n")
+	}
+
+	server := &http.Server{
+		Addr: *addr,
+		Handler: tifpdf2png.NewHandler(&tifpdf2png.ServerOptions{
+			Options:       opts,
+			OutputDir:     outputDir,
+			BaseURL:       *baseURL,
+			MaxUploadSize: *maxUpload << 20,
+			Timeout:       *timeout,
+			MaxConcurrent: *concurrency,
+			MaxDPI:        *maxDPI,
+			PageTTL:       *pageTTL,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Finish the conversions in progress on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	shutdown := make(chan struct{})
+	go func() {
+		defer close(shutdown)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("Failed to shut down server", "error", err)
+		}
+	}()
+
+	slog.Info("Serving conversions", "addr", *addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	<-shutdown
+	return 0
+}
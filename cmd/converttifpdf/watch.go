@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/dhushon/go-tifpdf2png"
+)
+
+// Subfolders of a watched folder that converted and failed documents are moved to
+const (
+	watchDoneDir   = "done"
+	watchFailedDir = "failed"
+)
+
+// watchConfig holds the settings of watch mode
+type watchConfig struct {
+	dir         string        // Watched folder
+	outputDir   string        // Pages of each document go to a subdirectory named after it
+	interval    time.Duration // Time between scans of the folder
+	settle      time.Duration // Time a file's size and modification time must stay unchanged
+	concurrency int
+}
+
+// fileState is the size and modification time of a file when it was last seen to change
+type fileState struct {
+	size    int64
+	modTime time.Time
+	since   time.Time
+}
+
+// runWatch converts the documents dropped into a folder until SIGINT or SIGTERM,
+// finishing the conversions in progress before it returns. Each document is converted
+// once it is fully written, then moved to the done or failed subfolder together with a
+// JSON sidecar holding its pages or error.
+func runWatch(wc *watchConfig, cfg *runConfig) error {
+	for _, sub := range []string{watchDoneDir, watchFailedDir} {
+		if err := os.MkdirAll(filepath.Join(wc.dir, sub), 0755); err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Files stay queued until they are moved away, so a file that can't be moved is not
+	// converted again
+	var mu sync.Mutex
+	queued := make(map[string]bool)
+
+	var wg sync.WaitGroup
+	work := make(chan string)
+	for range wc.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range work {
+				if processWatchedFile(path, wc, cfg) {
+					mu.Lock()
+					delete(queued, path)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	if !cfg.quiet {
+		fmt.Fprintf(os.Stderr, "Watching %s, writing pages to %s\n", wc.dir, wc.outputDir)
+	}
+	seen := make(map[string]fileState)
+	ticker := time.NewTicker(wc.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, path := range stableFiles(wc, seen) {
+			mu.Lock()
+			skip := queued[path]
+			queued[path] = true
+			mu.Unlock()
+			if skip {
+				continue
+			}
+			select {
+			case work <- path:
+			case <-ctx.Done():
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if !cfg.quiet {
+				fmt.Fprintf(os.Stderr, "Stopping; waiting for conversions in progress\n")
+			}
+			close(work)
+			wg.Wait()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// stableFiles scans the watched folder and returns the supported files whose size and
+// modification time have not changed for the settle time, updating seen
+func stableFiles(wc *watchConfig, seen map[string]fileState) []string {
+	entries, err := os.ReadDir(wc.dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ Failed to scan %s: %v\n", wc.dir, err)
+		return nil
+	}
+
+	now := time.Now()
+	present := make(map[string]bool)
+	var stable []string
+	for _, entry := range entries {
+		// Skip folders and hidden files, such as partial uploads
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), ".") || !tifpdf2png.IsSupported(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(wc.dir, entry.Name())
+		present[path] = true
+
+		state, ok := seen[path]
+		if !ok || state.size != info.Size() || !state.modTime.Equal(info.ModTime()) {
+			seen[path] = fileState{size: info.Size(), modTime: info.ModTime(), since: now}
+			continue
+		}
+		if now.Sub(state.since) >= wc.settle {
+			stable = append(stable, path)
+		}
+	}
+	for path := range seen {
+		if !present[path] {
+			delete(seen, path)
+		}
+	}
+	return stable
+}
+
+// processWatchedFile converts a document of the watched folder into its own output
+// subdirectory and moves it with its JSON sidecar to the done or failed subfolder,
+// reporting whether it was moved
+func processWatchedFile(path string, wc *watchConfig, cfg *runConfig) bool {
+	name := filepath.Base(path)
+	subdir, err := claimOutputDir(wc.outputDir, strings.TrimSuffix(name, filepath.Ext(name)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %s: creating output directory: %v\n", path, err)
+		return false
+	}
+
+	result := convertBatchInput(batchInput{path: path, subdir: subdir}, wc.outputDir, cfg)
+	destDir := filepath.Join(wc.dir, watchDoneDir)
+	if result.Error != "" {
+		destDir = filepath.Join(wc.dir, watchFailedDir)
+		// Drop the output subdirectory if no pages were written to it
+		os.Remove(filepath.Join(wc.outputDir, subdir))
+	}
+
+	dest, sidecar, err := claimDestination(destDir, name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %s: writing sidecar: %v\n", path, err)
+		return false
+	}
+	if err := writeSidecar(sidecar, result); err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %s: writing sidecar: %v\n", path, err)
+	}
+	if err := os.Rename(path, dest); err != nil {
+		fmt.Fprintf(os.Stderr, "✗ %s: moving to %s: %v\n", path, destDir, err)
+		return false
+	}
+	return true
+}
+
+// claimOutputDir creates the output subdirectory of a document, named after its stem
+// or, if taken, its stem with the first free -2, -3, ... suffix. Creating the directory
+// claims the name, so documents converted at the same time never share a directory.
+func claimOutputDir(outputDir string, stem string) (string, error) {
+	subdir := stem
+	for n := 2; ; n++ {
+		err := os.Mkdir(filepath.Join(outputDir, subdir), 0755)
+		if err == nil {
+			return subdir, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+		subdir = stem + "-" + strconv.Itoa(n)
+	}
+}
+
+// claimDestination picks the path a document is moved to in destDir, adding the first
+// free -2, -3, ... suffix if its name is taken, and creates its JSON sidecar. Creating
+// the sidecar exclusively claims the name, so documents moved at the same time never
+// overwrite each other.
+func claimDestination(destDir string, name string) (string, *os.File, error) {
+	stem, ext := strings.TrimSuffix(name, filepath.Ext(name)), filepath.Ext(name)
+	dest := filepath.Join(destDir, name)
+	for n := 2; ; n++ {
+		sidecar, err := os.OpenFile(dest+".json", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			if !exists(dest) {
+				return dest, sidecar, nil
+			}
+			// A document without a sidecar holds the name
+			sidecar.Close()
+			os.Remove(dest + ".json")
+		} else if !errors.Is(err, fs.ErrExist) {
+			return "", nil, err
+		}
+		dest = filepath.Join(destDir, stem+"-"+strconv.Itoa(n)+ext)
+	}
+}
+
+// writeSidecar writes the result of a watched document as indented JSON and closes the file
+func writeSidecar(f *os.File, result *batchResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err == nil {
+		_, err = f.Write(append(data, '\n'))
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// exists reports whether a file or directory exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dhushon/go-tifpdf2png"
+)
+
+// writeTestImage writes a small page with a black mark as PNG or, for .jpg paths, JPEG
+func writeTestImage(t *testing.T, path string) {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 16, 16))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	img.SetGray(4, 4, color.Gray{})
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create test image: %v", err)
+	}
+	defer f.Close()
+	if filepath.Ext(path) == ".jpg" {
+		err = jpeg.Encode(f, img, nil)
+	} else {
+		err = png.Encode(f, img)
+	}
+	if err != nil {
+		t.Fatalf("Failed to encode test image: %v", err)
+	}
+}
+
+// newTestWatch returns the watch settings of a temporary watched folder with its done
+// and failed subfolders
+func newTestWatch(t *testing.T) (*watchConfig, *runConfig) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, sub := range []string{watchDoneDir, watchFailedDir, "output"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", sub, err)
+		}
+	}
+	wc := &watchConfig{dir: dir, outputDir: filepath.Join(dir, "output"), interval: time.Millisecond, concurrency: 2}
+	return wc, &runConfig{opts: &tifpdf2png.Options{}, quiet: true}
+}
+
+func TestStableFiles(t *testing.T) {
+	wc, _ := newTestWatch(t)
+	path := filepath.Join(wc.dir, "scan.png")
+	writeTestImage(t, path)
+	for _, name := range []string{".partial.png", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(wc.dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	seen := make(map[string]fileState)
+	if stable := stableFiles(wc, seen); len(stable) != 0 {
+		t.Errorf("Expected no stable files on the first scan, got %v", stable)
+	}
+	if stable := stableFiles(wc, seen); len(stable) != 1 || stable[0] != path {
+		t.Errorf("Expected only %s to be stable, got %v", path, stable)
+	}
+
+	// A file still being written starts its settle time again
+	if err := os.WriteFile(path, []byte("growing"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite file: %v", err)
+	}
+	if stable := stableFiles(wc, seen); len(stable) != 0 {
+		t.Errorf("Expected a changed file not to be stable, got %v", stable)
+	}
+	wc.settle = time.Hour
+	if stable := stableFiles(wc, seen); len(stable) != 0 {
+		t.Errorf("Expected no stable files before the settle time, got %v", stable)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	stableFiles(wc, seen)
+	if len(seen) != 0 {
+		t.Errorf("Expected removed files to be forgotten, got %v", seen)
+	}
+}
+
+func TestProcessWatchedFile(t *testing.T) {
+	wc, cfg := newTestWatch(t)
+	good := filepath.Join(wc.dir, "scan.png")
+	bad := filepath.Join(wc.dir, "broken.pdf")
+	writeTestImage(t, good)
+	if err := os.WriteFile(bad, []byte("not a pdf"), 0644); err != nil {
+		t.Fatalf("Failed to write broken PDF: %v", err)
+	}
+
+	if !processWatchedFile(good, wc, cfg) || !processWatchedFile(bad, wc, cfg) {
+		t.Fatal("Expected both documents to be moved")
+	}
+
+	var result batchResult
+	data, err := os.ReadFile(filepath.Join(wc.dir, watchDoneDir, "scan.png.json"))
+	if err != nil {
+		t.Fatalf("Failed to read sidecar: %v", err)
+	}
+	if err := json.Unmarshal(data, &result); err != nil || len(result.Pages) != 1 || result.Error != "" {
+		t.Errorf("Unexpected sidecar %s: %v", data, err)
+	}
+	if !exists(filepath.Join(wc.dir, watchDoneDir, "scan.png")) || exists(good) {
+		t.Error("Expected the converted document to be moved to done")
+	}
+	if !exists(filepath.Join(wc.outputDir, "scan", "scan-page-0.png")) {
+		t.Error("Expected the page in the document's output subdirectory")
+	}
+
+	data, err = os.ReadFile(filepath.Join(wc.dir, watchFailedDir, "broken.pdf.json"))
+	if err != nil {
+		t.Fatalf("Failed to read sidecar: %v", err)
+	}
+	if err := json.Unmarshal(data, &result); err != nil || result.Error == "" {
+		t.Errorf("Expected an error in the sidecar, got %s", data)
+	}
+	if !exists(filepath.Join(wc.dir, watchFailedDir, "broken.pdf")) || exists(filepath.Join(wc.outputDir, "broken")) {
+		t.Error("Expected the failed document in failed and no output subdirectory")
+	}
+}
+
+func TestProcessWatchedFileNameCollisions(t *testing.T) {
+	wc, cfg := newTestWatch(t)
+	// A document of the same name was filed earlier, without a sidecar
+	writeTestImage(t, filepath.Join(wc.dir, watchDoneDir, "a.png"))
+
+	// Documents sharing a stem are converted at the same time
+	paths := []string{filepath.Join(wc.dir, "a.png"), filepath.Join(wc.dir, "a.jpg")}
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		writeTestImage(t, path)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !processWatchedFile(path, wc, cfg) {
+				t.Errorf("Expected %s to be moved", path)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Both pages are named a-page-0.png, so each needs its own subdirectory
+	for _, subdir := range []string{"a", "a-2"} {
+		if !exists(filepath.Join(wc.outputDir, subdir, "a-page-0.png")) {
+			t.Errorf("Expected a page in output/%s", subdir)
+		}
+	}
+
+	for _, name := range []string{"a.png", "a-2.png", "a-2.png.json", "a.jpg", "a.jpg.json"} {
+		if !exists(filepath.Join(wc.dir, watchDoneDir, name)) {
+			t.Errorf("Expected done/%s", name)
+		}
+	}
+	if exists(filepath.Join(wc.dir, watchDoneDir, "a.png.json")) {
+		t.Error("Expected the earlier a.png to keep its name without a sidecar")
+	}
+}
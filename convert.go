@@ -0,0 +1,116 @@
+package tifpdf2png
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsupportedFormat is returned when a file's extension matches no supported format
+var ErrUnsupportedFormat = errors.New("unsupported file type")
+
+// errStopped stops a conversion whose pages are no longer wanted
+var errStopped = errors.New("conversion stopped")
+
+// IsSupported reports whether a file has the extension of a TIFF, PDF, document or
+// raster image supported by ConvertFileWithOptions
+func IsSupported(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".pdf", ".tif", ".tiff":
+		return true
+	}
+	return IsDocument(filename) || IsRasterImage(filename)
+}
+
+// ConvertFileWithOptions converts a TIFF, PDF, document or raster image to PNG, choosing
+// the converter from the file extension, and returns ImageDetail slice
+func ConvertFileWithOptions(filename string, destpath string, prefix string, opts *Options) ([]*ImageDetail, error) {
+	imageDetails, _, err := convertFile(fileSource(filename), destpath, prefix, opts, false)
+	return imageDetails, err
+}
+
+// ConvertFileWithDocumentInfo converts a file like ConvertFileWithOptions and also returns
+// the metadata, outline and page sizes of PDFs and documents. The DocumentInfo is nil
+// for TIFFs and raster images.
+func ConvertFileWithDocumentInfo(filename string, destpath string, prefix string, opts *Options) ([]*ImageDetail, *DocumentInfo, error) {
+	return convertFile(fileSource(filename), destpath, prefix, opts, true)
+}
+
+// ConvertReaderWithOptions reads a document into memory and converts it like
+// ConvertFileWithOptions, choosing the converter from the format DetectFormat finds in
+// its content
+func ConvertReaderWithOptions(r io.Reader, destpath string, prefix string, opts *Options) ([]*ImageDetail, error) {
+	src, err := readerSource(r)
+	if err != nil {
+		return nil, err
+	}
+	imageDetails, _, err := convertFile(src, destpath, prefix, opts, false)
+	return imageDetails, err
+}
+
+// ConvertReaderWithDocumentInfo reads a document into memory and converts it like
+// ConvertFileWithDocumentInfo
+func ConvertReaderWithDocumentInfo(r io.Reader, destpath string, prefix string, opts *Options) ([]*ImageDetail, *DocumentInfo, error) {
+	src, err := readerSource(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return convertFile(src, destpath, prefix, opts, true)
+}
+
+// readerSource reads a document into memory and detects its format
+func readerSource(r io.Reader) (*source, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	ext := DetectFormat(data)
+	if ext == "" {
+		return nil, fmt.Errorf("%w: content not recognized", ErrUnsupportedFormat)
+	}
+	return memorySource(data, ext), nil
+}
+
+// ConvertFilePages converts a file like ConvertFileWithOptions and yields the ImageDetail
+// of each page as soon as the page is saved, followed by the error if the conversion
+// fails. Breaking out of the loop stops the conversion.
+func ConvertFilePages(filename string, destpath string, prefix string, opts *Options) iter.Seq2[*ImageDetail, error] {
+	return func(yield func(*ImageDetail, error) bool) {
+		pageOpts := *opts.orDefault()
+		callerOpts := pageOpts
+		stopped := false
+		pageOpts.OnPage = func(detail *ImageDetail) error {
+			if err := callerOpts.pageSaved(detail); err != nil {
+				return err
+			}
+			if !yield(detail, nil) {
+				stopped = true
+				return errStopped
+			}
+			return nil
+		}
+
+		if _, _, err := convertFile(fileSource(filename), destpath, prefix, &pageOpts, false); err != nil && !stopped {
+			yield(nil, err)
+		}
+	}
+}
+
+// convertFile routes a source to the converter for its extension
+func convertFile(src *source, destpath string, prefix string, opts *Options, withInfo bool) ([]*ImageDetail, *DocumentInfo, error) {
+	switch {
+	case src.ext == ".pdf" || documentFormats[src.ext] != nil:
+		return convertPdf(src, destpath, prefix, opts, withInfo)
+	case src.ext == ".tif" || src.ext == ".tiff":
+		imageDetails, err := convertTiff(src, destpath, prefix, opts)
+		return imageDetails, nil, err
+	case rasterImageFormats[src.ext] != "":
+		imageDetails, err := convertImage(src, destpath, prefix, opts)
+		return imageDetails, nil, err
+	default:
+		return nil, nil, fmt.Errorf("%w %q", ErrUnsupportedFormat, src.ext)
+	}
+}
@@ -0,0 +1,156 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertFileWithOptions(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "doc.pdf")
+	writeTestPdf(t, pdfPath, []string{"One", "Two"})
+	tiffPath := filepath.Join(dir, "scan.TIF")
+	writeRawTestTiff(t, tiffPath, []testTiffPage{{width: 2, height: 2, pixels: []byte{0, 255, 255, 255}}})
+
+	details, info, err := ConvertFileWithDocumentInfo(pdfPath, dir, "doc-", nil)
+	if err != nil {
+		t.Fatalf("ConvertFileWithDocumentInfo failed for PDF: %v", err)
+	}
+	if len(details) != 2 || info == nil || info.Pages != 2 {
+		t.Errorf("Expected 2 PDF pages with document info, got %d pages and %+v", len(details), info)
+	}
+
+	details, info, err = ConvertFileWithDocumentInfo(tiffPath, dir, "scan-", nil)
+	if err != nil {
+		t.Fatalf("ConvertFileWithDocumentInfo failed for TIFF: %v", err)
+	}
+	if len(details) != 1 || details[0].Tiff == nil || info != nil {
+		t.Errorf("Expected 1 TIFF page without document info, got %d pages and %+v", len(details), info)
+	}
+
+	textPath := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(textPath, []byte("notes"), 0644); err != nil {
+		t.Fatalf("Failed to write text file: %v", err)
+	}
+	if IsSupported(textPath) || !IsSupported(tiffPath) || !IsSupported("book.epub") || !IsSupported("photo.JPG") {
+		t.Error("IsSupported does not match the supported extensions")
+	}
+	if _, err := ConvertFileWithOptions(textPath, dir, "notes-", nil); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat, got %v", err)
+	}
+}
+
+func TestConvertFilePages(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "doc.pdf")
+	writeTestPdf(t, pdfPath, []string{"One", "Two", "Three"})
+
+	// OnPage sees each page as soon as it is saved
+	var saved []int
+	opts := &Options{OnPage: func(detail *ImageDetail) error {
+		if _, err := os.Stat(detail.URL); err != nil {
+			t.Errorf("Page %d not saved before OnPage: %v", detail.Page, err)
+		}
+		saved = append(saved, detail.Page)
+		return nil
+	}}
+	if _, err := ConvertFileWithOptions(pdfPath, dir, "doc-", opts); err != nil {
+		t.Fatalf("ConvertFileWithOptions failed: %v", err)
+	}
+	if len(saved) != 3 || saved[0] != 1 || saved[2] != 3 {
+		t.Errorf("Expected OnPage for pages 1-3, got %v", saved)
+	}
+
+	// An OnPage error stops the conversion
+	stop := errors.New("stop")
+	_, err := ConvertFileWithOptions(pdfPath, dir, "doc-", &Options{OnPage: func(*ImageDetail) error { return stop }})
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected the OnPage error, got %v", err)
+	}
+
+	// Breaking out of the iterator stops after the first page
+	pages := 0
+	for detail, err := range ConvertFilePages(pdfPath, dir, "iter-", nil) {
+		if err != nil {
+			t.Fatalf("ConvertFilePages failed: %v", err)
+		}
+		pages++
+		if detail.Page == 1 {
+			break
+		}
+	}
+	if pages != 1 {
+		t.Errorf("Expected to stop after 1 page, got %d", pages)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "iter-1.png")); !os.IsNotExist(err) {
+		t.Errorf("Expected page 2 not to be converted after breaking, got %v", err)
+	}
+
+	for _, err := range ConvertFilePages(filepath.Join(dir, "missing.pdf"), dir, "missing-", nil) {
+		if err == nil {
+			t.Error("Expected an error for a missing file")
+		}
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	dir := t.TempDir()
+	epubPath := filepath.Join(dir, "book.epub")
+	writeTestZip(t, epubPath, [][2]string{{"mimetype", "application/epub+zip"}, {"content.opf", "<package/>"}})
+	cbzPath := filepath.Join(dir, "comic.cbz")
+	writeTestZip(t, cbzPath, [][2]string{{"001.png", "not really a png"}})
+	epub, _ := os.ReadFile(epubPath)
+	cbz, _ := os.ReadFile(cbzPath)
+	webp, _ := base64.StdEncoding.DecodeString(testWebP)
+
+	for want, data := range map[string][]byte{
+		".pdf":  []byte("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n"),
+		".tif":  []byte("II*\x00\x08\x00\x00\x00"),
+		".jpg":  []byte("\xff\xd8\xff\xe0\x00\x10JFIF"),
+		".png":  []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"),
+		".gif":  []byte("GIF89a\x01\x00\x01\x00"),
+		".webp": webp,
+		".fb2":  []byte(`<?xml version="1.0"?><FictionBook xmlns="http://www.gribuser.ru/xml/fictionbook/2.0">`),
+		".epub": epub,
+		".cbz":  cbz,
+		"":      []byte("plain text notes"),
+	} {
+		if got := DetectFormat(data); got != want {
+			t.Errorf("DetectFormat(%q...) = %q, want %q", data[:min(len(data), 8)], got, want)
+		}
+	}
+}
+
+func TestConvertReader(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "doc.pdf")
+	writeTestPdf(t, pdfPath, []string{"One", "Two"})
+	data, err := os.ReadFile(pdfPath)
+	if err != nil {
+		t.Fatalf("Failed to read test PDF: %v", err)
+	}
+
+	outDir := filepath.Join(dir, "out")
+	if err := os.Mkdir(outDir, 0755); err != nil {
+		t.Fatalf("Failed to create output directory: %v", err)
+	}
+	details, info, err := ConvertReaderWithDocumentInfo(bytes.NewReader(data), outDir, "doc-", nil)
+	if err != nil {
+		t.Fatalf("ConvertReaderWithDocumentInfo failed: %v", err)
+	}
+	if len(details) != 2 || info == nil || info.Pages != 2 || len(info.PageLabels) != 2 {
+		t.Fatalf("Expected 2 pages with document info, got %d pages and %+v", len(details), info)
+	}
+	if details[1].URL != filepath.Join(outDir, "doc-1.png") {
+		t.Errorf("Unexpected URL %q", details[1].URL)
+	}
+
+	if _, err := ConvertReaderWithOptions(strings.NewReader("plain text notes"), outDir, "notes-", nil); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Expected ErrUnsupportedFormat for unrecognized content, got %v", err)
+	}
+}
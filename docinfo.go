@@ -0,0 +1,195 @@
+package tifpdf2png
+
+import (
+	"errors"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gen2brain/go-fitz"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// pdfDatePattern matches PDF date strings such as D:20240131120000+01'00'
+var pdfDatePattern = regexp.MustCompile(`^D:(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?([Zz+\-])?(\d{2})?'?(\d{2})?'?$`)
+
+// readDocumentInfo collects document level metadata from an open document. The page
+// labels, modification date and page boxes of PDFs are read with pdfcpu, from memory
+// when the document was decrypted or flattened.
+func readDocumentInfo(doc *pdfDocument, filename string) *DocumentInfo {
+	metadata := doc.Metadata()
+	lookup := func(key string) string {
+		value, _, _ := strings.Cut(metadata[key], "\x00")
+		return strings.TrimSpace(value)
+	}
+
+	pageCount := doc.NumPage()
+	var ctx *model.Context
+	if doc.format == nil {
+		var err error
+		ctx, err = readPdfContext(filename, doc.raw)
+		if err != nil {
+			slog.Warn("readDocumentInfo: read PDF structure", "error", err)
+		}
+	}
+
+	info := &DocumentInfo{
+		Format:       lookup("format"),
+		Encryption:   lookup("encryption"),
+		Title:        lookup("title"),
+		Author:       lookup("author"),
+		Subject:      lookup("subject"),
+		Keywords:     lookup("keywords"),
+		Creator:      lookup("creator"),
+		Producer:     lookup("producer"),
+		CreationDate: normalizePdfDate(lookup("creationDate")),
+		ModDate:      normalizePdfDate(lookup("modDate")),
+		Pages:        pageCount,
+	}
+	if info.Encryption == "None" {
+		info.Encryption = ""
+	}
+	if doc.decrypted {
+		info.Encryption = "Standard (decrypted with password)"
+	}
+	if doc.format != nil {
+		// MuPDF reports CBZ archives as "zip"
+		if info.Format == "" || info.Format == "zip" {
+			info.Format = doc.format.name
+		}
+		info.Reflowable = doc.format.reflowable
+	}
+	if ctx != nil {
+		info.PageLabels = pageLabels(readPageLabelRanges(ctx), pageCount)
+		info.PageSizes = readPageSizes(ctx)
+		if info.ModDate == "" {
+			// go-fitz looks up "info:modDate" which never matches the ModDate key
+			info.ModDate = normalizePdfDate(infoString(ctx, "ModDate"))
+		}
+	} else {
+		info.PageLabels = pageLabels(nil, pageCount)
+	}
+
+	for pageNum, annotations := range doc.annotations {
+		for _, annotation := range annotations {
+			if annotation.Type != "Widget" || annotation.FieldName == "" || hasFormField(info.FormFields, annotation.FieldName) {
+				continue
+			}
+			info.FormFields = append(info.FormFields, FormField{
+				Name:  annotation.FieldName,
+				Type:  annotation.FieldType,
+				Value: annotation.FieldValue,
+				Page:  pageNum + 1,
+			})
+		}
+	}
+
+	toc, err := doc.ToC()
+	if err != nil && !errors.Is(err, fitz.ErrLoadOutline) {
+		slog.Warn("readDocumentInfo: load outline", "error", err)
+	}
+	for _, entry := range toc {
+		info.Outline = append(info.Outline, OutlineEntry{
+			Level: entry.Level,
+			Title: entry.Title,
+			Page:  entry.Page + 1,
+			URI:   entry.URI,
+		})
+	}
+
+	// MuPDF lays out other documents in whole points
+	for pageNum := 0; ctx == nil && pageNum < pageCount; pageNum++ {
+		bounds, err := doc.Bound(pageNum)
+		if err != nil {
+			slog.Warn("readDocumentInfo: page bounds", "page", pageNum, "error", err)
+			continue
+		}
+		info.PageSizes = append(info.PageSizes, PageSize{
+			Page:   pageNum + 1,
+			Width:  float64(bounds.Dx()),
+			Height: float64(bounds.Dy()),
+		})
+	}
+
+	return info
+}
+
+// readPageSizes returns the size in points of the crop box, or else the media box, of
+// every page, as displayed after the page's rotation
+func readPageSizes(ctx *model.Context) []PageSize {
+	var sizes []PageSize
+	for pageNr := 1; pageNr <= ctx.PageCount; pageNr++ {
+		_, _, inherited, err := ctx.PageDict(pageNr, false)
+		if err != nil || inherited == nil {
+			slog.Warn("readPageSizes: page dictionary", "page", pageNr, "error", err)
+			continue
+		}
+		box := pageBox(inherited)
+		width, height := box[2]-box[0], box[3]-box[1]
+		if rotate := (inherited.Rotate%360 + 360) % 360; rotate == 90 || rotate == 270 {
+			width, height = height, width
+		}
+		sizes = append(sizes, PageSize{Page: pageNr, Width: width, Height: height})
+	}
+	return sizes
+}
+
+// infoString returns a text entry of the document information dictionary
+func infoString(ctx *model.Context, key string) string {
+	if ctx.Info == nil {
+		return ""
+	}
+	d, err := ctx.DereferenceDict(*ctx.Info)
+	if err != nil || d == nil {
+		return ""
+	}
+	value, err := ctx.DereferenceText(d[key])
+	if err != nil {
+		return ""
+	}
+	return value
+}
+
+// normalizePdfDate converts a PDF date string to RFC 3339, returning the input
+// unchanged if it cannot be parsed
+func normalizePdfDate(value string) string {
+	m := pdfDatePattern.FindStringSubmatch(value)
+	if m == nil {
+		return value
+	}
+
+	digits := func(s string, def int) int {
+		if s == "" {
+			return def
+		}
+		n := 0
+		for _, c := range s {
+			n = n*10 + int(c-'0')
+		}
+		return n
+	}
+
+	loc := time.UTC
+	if m[7] == "+" || m[7] == "-" {
+		offset := digits(m[8], 0)*3600 + digits(m[9], 0)*60
+		if m[7] == "-" {
+			offset = -offset
+		}
+		loc = time.FixedZone("", offset)
+	}
+
+	t := time.Date(digits(m[1], 0), time.Month(digits(m[2], 1)), digits(m[3], 1),
+		digits(m[4], 0), digits(m[5], 0), digits(m[6], 0), 0, loc)
+	return t.Format(time.RFC3339)
+}
+
+// hasFormField reports whether a field with the given name is already listed
+func hasFormField(fields []FormField, name string) bool {
+	for _, field := range fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
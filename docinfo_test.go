@@ -0,0 +1,162 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+// writeCompressedTestPdf rewrites a PDF with its objects in object streams and a
+// cross-reference stream, as PDF 1.5 and later writers do
+func writeCompressedTestPdf(t *testing.T, destPath string, srcPath string) {
+	t.Helper()
+	ctx, err := readPdfContext(srcPath, nil)
+	if err != nil {
+		t.Fatalf("Failed to read test PDF: %v", err)
+	}
+	ctx.WriteObjectStream = true
+	ctx.WriteXRefStream = true
+
+	var out bytes.Buffer
+	if err := api.WriteContext(ctx, &out); err != nil {
+		t.Fatalf("Failed to write compressed PDF: %v", err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("/ObjStm")) {
+		t.Fatal("Expected the PDF to use object streams")
+	}
+	if err := os.WriteFile(destPath, out.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write compressed PDF: %v", err)
+	}
+}
+
+func TestPageLabels(t *testing.T) {
+	dir := t.TempDir()
+	kids := make([]string, 7)
+	var objects []string
+	for i := range kids {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+i)
+	}
+	objects = append(objects,
+		// The label tree is split into /Kids, with an indirect label and a prefix that
+		// looks like a PDF keyword
+		"<< /Type /Catalog /Pages 2 0 R /PageLabels << /Kids [3 0 R 4 0 R] >> >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count 7 /MediaBox [0 0 595.28 841.89] >>", strings.Join(kids, " ")),
+		"<< /Limits [0 3] /Nums [0 << /S /r >> 3 5 0 R] >>",
+		"<< /Limits [5 5] /Nums [5 << /P (App\\) /PageLabels-) /S /A /St 2 >>] >>",
+		"<< /S /D >>",
+	)
+	for i := range kids {
+		page := "<< /Type /Page /Parent 2 0 R >>"
+		if i == 6 {
+			page = "<< /Type /Page /Parent 2 0 R /CropBox [10 10 310.5 410.25] /Rotate 90 >>"
+		}
+		objects = append(objects, page)
+	}
+	plainPath := filepath.Join(dir, "plain.pdf")
+	writeRawTestPdf(t, plainPath, objects)
+	pdfPath := filepath.Join(dir, "labels.pdf")
+	writeCompressedTestPdf(t, pdfPath, plainPath)
+
+	info, err := ReadPdfDocumentInfo(pdfPath, nil)
+	if err != nil {
+		t.Fatalf("ReadPdfDocumentInfo failed: %v", err)
+	}
+	want := []string{"i", "ii", "iii", "1", "2", "App) /PageLabels-B", "App) /PageLabels-C"}
+	if !reflect.DeepEqual(info.PageLabels, want) {
+		t.Errorf("Expected labels %v, got %v", want, info.PageLabels)
+	}
+
+	// Page sizes keep fractional points and follow the crop box and rotation
+	if len(info.PageSizes) != 7 {
+		t.Fatalf("Expected 7 page sizes, got %d", len(info.PageSizes))
+	}
+	if size := info.PageSizes[0]; size.Width != 595.28 || size.Height != 841.89 {
+		t.Errorf("Expected 595.28x841.89 points, got %vx%v", size.Width, size.Height)
+	}
+	if size := info.PageSizes[6]; size.Width != 400.25 || size.Height != 300.5 {
+		t.Errorf("Expected a rotated 400.25x300.5 point crop box, got %vx%v", size.Width, size.Height)
+	}
+
+	// The modification date is read from the Info dictionary in the object streams
+	if _, err := time.Parse(time.RFC3339, info.ModDate); err != nil {
+		t.Errorf("Expected an RFC 3339 modification date, got %q", info.ModDate)
+	}
+
+	// A huge /St is ignored instead of growing the labels without bound
+	hugePath := filepath.Join(dir, "huge.pdf")
+	writeRawTestPdf(t, hugePath, []string{
+		"<< /Type /Catalog /Pages 2 0 R /PageLabels << /Nums [0 << /S /R /St 1000000000000 >> 1 << /S /A /St 1000000 >>] >> >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 612 792] >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+		"<< /Type /Page /Parent 2 0 R >>",
+	})
+	info, err = ReadPdfDocumentInfo(hugePath, nil)
+	if err != nil {
+		t.Fatalf("ReadPdfDocumentInfo failed: %v", err)
+	}
+	if want := []string{"I", "1000000"}; !reflect.DeepEqual(info.PageLabels, want) {
+		t.Errorf("Expected labels %v, got %v", want, info.PageLabels)
+	}
+	for n, want := range map[int]string{4999: "MMMMCMXCIX", 5000: "5000"} {
+		if got := romanNumeral(n); got != want {
+			t.Errorf("romanNumeral(%d) = %q, want %q", n, got, want)
+		}
+	}
+	if got := alphabeticLabel(26*100 + 1); got != "2601" {
+		t.Errorf("Expected a decimal label past the alphabetic bound, got %q", got)
+	}
+
+	// Documents without a /PageLabels tree use decimal page numbers
+	got := pageLabels(nil, 3)
+	if !reflect.DeepEqual(got, []string{"1", "2", "3"}) {
+		t.Errorf("Expected default labels, got %v", got)
+	}
+}
+
+func TestNormalizePdfDate(t *testing.T) {
+	for input, want := range map[string]string{
+		"D:20240131120000+01'00'": "2024-01-31T12:00:00+01:00",
+		"D:20240131120000Z":       "2024-01-31T12:00:00Z",
+		"D:2024":                  "2024-01-01T00:00:00Z",
+		"yesterday":               "yesterday",
+	} {
+		if got := normalizePdfDate(input); got != want {
+			t.Errorf("normalizePdfDate(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestReadPdfDocumentInfo(t *testing.T) {
+	pdfPath := filepath.Join(t.TempDir(), "info.pdf")
+	writeTestPdf(t, pdfPath, []string{"One", "Two"})
+
+	info, err := ReadPdfDocumentInfo(pdfPath, nil)
+	if err != nil {
+		t.Fatalf("ReadPdfDocumentInfo failed: %v", err)
+	}
+
+	if info.Format != "PDF 1.4" {
+		t.Errorf("Expected format 'PDF 1.4', got '%s'", info.Format)
+	}
+	if info.Pages != 2 {
+		t.Errorf("Expected 2 pages, got %d", info.Pages)
+	}
+	if !reflect.DeepEqual(info.PageLabels, []string{"1", "2"}) {
+		t.Errorf("Expected default page labels, got %v", info.PageLabels)
+	}
+	if len(info.PageSizes) != 2 {
+		t.Fatalf("Expected 2 page sizes, got %d", len(info.PageSizes))
+	}
+	for _, size := range info.PageSizes {
+		if size.Width != 612 || size.Height != 792 {
+			t.Errorf("Page %d: expected 612x792 points, got %vx%v", size.Page, size.Width, size.Height)
+		}
+	}
+}
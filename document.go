@@ -0,0 +1,56 @@
+package tifpdf2png
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// documentFormat describes a non-PDF document format that MuPDF opens
+type documentFormat struct {
+	name string // Format name reported when MuPDF has none, such as "CBZ"
+
+	// reflowable is set for formats without fixed pages, which MuPDF lays out at its
+	// default page size of 450x600pt, so their page count depends on that layout
+	reflowable bool
+
+	// imagePages is set for formats whose pages are each a single image, which are
+	// extracted at their native resolution rather than rendered
+	imagePages bool
+}
+
+// documentFormats maps the extensions of the other document formats MuPDF opens to their format
+var documentFormats = map[string]*documentFormat{
+	".xps":  {name: "XPS"},
+	".oxps": {name: "XPS"},
+	".epub": {name: "EPUB", reflowable: true},
+	".fb2":  {name: "FictionBook2", reflowable: true},
+	".mobi": {name: "MOBI", reflowable: true},
+	".cbz":  {name: "CBZ", imagePages: true},
+}
+
+// documentFormatOf returns the format of a non-PDF document, or nil for PDFs and other files
+func documentFormatOf(filename string) *documentFormat {
+	return documentFormats[strings.ToLower(filepath.Ext(filename))]
+}
+
+// IsDocument reports whether a file has the extension of an XPS, EPUB, CBZ, FB2 or MOBI
+// document supported by ConvertDocumentToPngWithOptions
+func IsDocument(filename string) bool {
+	return documentFormatOf(filename) != nil
+}
+
+// ConvertDocumentToPngWithOptions converts an XPS, EPUB, CBZ, FB2 or MOBI document to
+// PNG through the PDF rendering path and returns ImageDetail slice. Reflowable formats
+// (EPUB, FB2, MOBI) are laid out by MuPDF at 450x600pt pages, and CBZ pages are
+// extracted at the native resolution of their images. Options that only apply to PDFs,
+// such as passwords and annotations, are ignored.
+func ConvertDocumentToPngWithOptions(filename string, destpath string, prefix string, opts *Options) ([]*ImageDetail, error) {
+	imageDetails, _, err := convertPdf(fileSource(filename), destpath, prefix, opts, false)
+	return imageDetails, err
+}
+
+// ConvertDocumentToPngWithDocumentInfo converts an XPS, EPUB, CBZ, FB2 or MOBI document
+// to PNG and also returns its metadata, outline and page sizes
+func ConvertDocumentToPngWithDocumentInfo(filename string, destpath string, prefix string, opts *Options) ([]*ImageDetail, *DocumentInfo, error) {
+	return convertPdf(fileSource(filename), destpath, prefix, opts, true)
+}
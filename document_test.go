@@ -0,0 +1,136 @@
+package tifpdf2png
+
+import (
+	"archive/zip"
+	"bytes"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestZip writes an uncompressed archive holding the named files in order
+func writeTestZip(t *testing.T, destPath string, files [][2]string) {
+	t.Helper()
+
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	for _, file := range files {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: file[0], Method: zip.Store})
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", file[0], err)
+		}
+		if _, err := f.Write([]byte(file[1])); err != nil {
+			t.Fatalf("Failed to write %s: %v", file[0], err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+	if err := os.WriteFile(destPath, b.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write test archive: %v", err)
+	}
+}
+
+func TestConvertEpub(t *testing.T) {
+	dir := t.TempDir()
+	epubPath := filepath.Join(dir, "book.epub")
+	writeTestZip(t, epubPath, [][2]string{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles><rootfile full-path="content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`},
+		{"content.opf", `<?xml version="1.0"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="2.0" unique-identifier="id">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:title>Test Book</dc:title><dc:creator>Jane Doe</dc:creator><dc:identifier id="id">test</dc:identifier>
+</metadata>
+<manifest><item id="c1" href="c1.xhtml" media-type="application/xhtml+xml"/></manifest>
+<spine><itemref idref="c1"/></spine>
+</package>`},
+		{"c1.xhtml", `<?xml version="1.0"?>
+<html xmlns="http://www.w3.org/1999/xhtml"><body><h1>Chapter One</h1><p>Hello world.</p></body></html>`},
+	})
+
+	if !IsDocument(epubPath) {
+		t.Error("Expected EPUB to be a document")
+	}
+
+	details, info, err := ConvertDocumentToPngWithDocumentInfo(epubPath, dir, "book-", &Options{ExtractText: true})
+	if err != nil {
+		t.Fatalf("ConvertDocumentToPngWithDocumentInfo failed: %v", err)
+	}
+	if len(details) != 1 || info.Pages != 1 {
+		t.Fatalf("Expected 1 page, got %d (info %d)", len(details), info.Pages)
+	}
+	if info.Format != "EPUB" || !info.Reflowable {
+		t.Errorf("Expected reflowable EPUB, got format %q reflowable %v", info.Format, info.Reflowable)
+	}
+	if info.Title != "Test Book" || info.Author != "Jane Doe" {
+		t.Errorf("Expected title and author from the package metadata, got %q by %q", info.Title, info.Author)
+	}
+	if info.PageSizes[0].Width != 450 || info.PageSizes[0].Height != 600 {
+		t.Errorf("Expected 450x600pt layout, got %+v", info.PageSizes[0])
+	}
+	if details[0].Text == nil || !bytes.Contains([]byte(details[0].Text.Content), []byte("Hello world.")) {
+		t.Errorf("Expected page text, got %+v", details[0].Text)
+	}
+}
+
+func TestConvertCbz(t *testing.T) {
+	dir := t.TempDir()
+	cbzPath := filepath.Join(dir, "comic.cbz")
+
+	var page bytes.Buffer
+	if err := png.Encode(&page, image.NewGray(image.Rect(0, 0, 30, 40))); err != nil {
+		t.Fatalf("Failed to encode page: %v", err)
+	}
+	writeTestZip(t, cbzPath, [][2]string{{"001.png", page.String()}, {"002.png", page.String()}})
+
+	details, info, err := ConvertDocumentToPngWithDocumentInfo(cbzPath, dir, "comic-", nil)
+	if err != nil {
+		t.Fatalf("ConvertDocumentToPngWithDocumentInfo failed: %v", err)
+	}
+	if len(details) != 2 {
+		t.Fatalf("Expected a page per image, got %d", len(details))
+	}
+	if info.Format != "CBZ" || info.Reflowable {
+		t.Errorf("Expected fixed-layout CBZ, got format %q reflowable %v", info.Format, info.Reflowable)
+	}
+	for _, detail := range details {
+		if !detail.Extracted || detail.Width != 30 || detail.Height != 40 {
+			t.Errorf("Page %d: expected extracted 30x40 image, got %dx%d (extracted %v)", detail.Page, detail.Width, detail.Height, detail.Extracted)
+		}
+	}
+}
+
+func TestConvertFictionBook(t *testing.T) {
+	dir := t.TempDir()
+	fb2Path := filepath.Join(dir, "story.fb2")
+	fb2 := `<?xml version="1.0" encoding="UTF-8"?>
+<FictionBook xmlns="http://www.gribuser.ru/xml/fictionbook/2.0">
+<description><title-info><book-title>Short Story</book-title></title-info></description>
+<body><section><p>Once upon a time.</p></section></body>
+</FictionBook>`
+	if err := os.WriteFile(fb2Path, []byte(fb2), 0644); err != nil {
+		t.Fatalf("Failed to write FB2: %v", err)
+	}
+
+	info, err := ReadPdfDocumentInfo(fb2Path, nil)
+	if err != nil {
+		t.Fatalf("ReadPdfDocumentInfo failed: %v", err)
+	}
+	if info.Format != "FictionBook2" || info.Title != "Short Story" || !info.Reflowable {
+		t.Errorf("Unexpected FB2 info %+v", info)
+	}
+
+	details, err := ConvertDocumentToPngWithOptions(fb2Path, dir, "story-", nil)
+	if err != nil {
+		t.Fatalf("ConvertDocumentToPngWithOptions failed: %v", err)
+	}
+	if len(details) != 1 || details[0].Width == 0 {
+		t.Errorf("Expected a rendered page, got %+v", details)
+	}
+}
@@ -0,0 +1,172 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	_ "image/jpeg" // MuPDF passes JPEG images through unchanged
+	_ "image/png"
+	"log/slog"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gen2brain/go-fitz"
+)
+
+// fullPageCoverage is the fraction of the page an image must cover to be treated as
+// the page itself rather than an illustration on it
+const fullPageCoverage = 0.95
+
+// cssPixelsPerPoint converts CSS pixels (96 per inch) used in MuPDF's HTML to points
+const cssPixelsPerPoint = 96.0 / pdfPointsPerInch
+
+// htmlImagePattern matches an image in MuPDF's structured text HTML, positioned by a CSS matrix
+var htmlImagePattern = regexp.MustCompile(`<img style="[^"]*?transform:matrix\(([^)]*)\)[^"]*" src="data:image/[a-z]+;base64,([^"]*)">`)
+
+var (
+	// svgUndrawnPatterns match the parts of MuPDF's SVG that are only referenced, such as
+	// glyph outlines and clipping paths, in the order they are removed
+	svgUndrawnPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?s)<defs>.*?</defs>`),
+		regexp.MustCompile(`(?s)<clipPath\b.*?</clipPath>`),
+		regexp.MustCompile(`(?s)<mask\b.*?</mask>`),
+	}
+
+	// svgDrawnPattern matches the elements of MuPDF's SVG that paint on the page: vector
+	// paths, glyphs of visible text and images
+	svgDrawnPattern = regexp.MustCompile(`<(path|use|text|image)\b`)
+)
+
+// pageImage is an image embedded in a page, placed in PDF points from the top left of the page
+type pageImage struct {
+	img           image.Image
+	left, top     float64
+	width, height float64
+	axisAligned   bool
+}
+
+// embeddedPageImage is an embedded raster that makes up a whole page
+type embeddedPageImage struct {
+	img     image.Image
+	scale   float64 // Output pixels per PDF point
+	originX int     // Left edge of the image on the page in output pixels
+	originY int     // Top edge of the image on the page in output pixels
+}
+
+// fullPageImage returns the page's only image at its native resolution when it covers
+// the whole page and nothing else is drawn, so scanned pages wrapped in a PDF are not
+// resampled. Pages with visible text, vector paths or annotations over the image, such
+// as stamps or Bates numbers, return nil to be rendered; invisible OCR text is kept as
+// the text layer.
+func fullPageImage(doc *fitz.Document, pageNum int) *embeddedPageImage {
+	bounds, err := doc.Bound(pageNum)
+	if err != nil || bounds.Dx() <= 0 || bounds.Dy() <= 0 {
+		return nil
+	}
+
+	pageHTML, err := doc.HTML(pageNum, false)
+	if err != nil {
+		slog.Warn("fullPageImage: page HTML", "page", pageNum, "error", err)
+		return nil
+	}
+
+	images := parsePageImages(pageHTML)
+	if len(images) != 1 || !images[0].axisAligned || images[0].img == nil {
+		return nil
+	}
+	placed := images[0]
+
+	pageWidth, pageHeight := float64(bounds.Dx()), float64(bounds.Dy())
+	overlapX := math.Min(placed.left+placed.width, pageWidth) - math.Max(placed.left, 0)
+	overlapY := math.Min(placed.top+placed.height, pageHeight) - math.Max(placed.top, 0)
+	if overlapX <= 0 || overlapY <= 0 || overlapX*overlapY < fullPageCoverage*pageWidth*pageHeight {
+		return nil
+	}
+
+	pageSVG, err := doc.SVG(pageNum)
+	if err != nil {
+		slog.Warn("fullPageImage: page SVG", "page", pageNum, "error", err)
+		return nil
+	}
+	if drawnElements(pageSVG) != 1 {
+		return nil
+	}
+
+	scale := float64(placed.img.Bounds().Dx()) / placed.width
+	return &embeddedPageImage{
+		img:     placed.img,
+		scale:   scale,
+		originX: int(math.Round(placed.left * scale)),
+		originY: int(math.Round(placed.top * scale)),
+	}
+}
+
+// drawnElements counts the paths, glyphs and images MuPDF's SVG of a page paints, leaving
+// out glyph outlines, clipping paths and masks that are only referenced
+func drawnElements(pageSVG string) int {
+	for _, pattern := range svgUndrawnPatterns {
+		pageSVG = pattern.ReplaceAllString(pageSVG, "")
+	}
+	return len(svgDrawnPattern.FindAllStringIndex(pageSVG, -1))
+}
+
+// parsePageImages decodes the images of MuPDF's structured text HTML along with their
+// placement. Images that cannot be decoded are returned with a nil img.
+func parsePageImages(pageHTML string) []pageImage {
+	var images []pageImage
+
+	for _, m := range htmlImagePattern.FindAllStringSubmatch(pageHTML, -1) {
+		var matrix [6]float64
+		fields := strings.Split(m[1], ",")
+		if len(fields) != 6 {
+			continue
+		}
+		for i, field := range fields {
+			matrix[i], _ = strconv.ParseFloat(strings.TrimSpace(field), 64)
+		}
+
+		encoded := strings.Join(strings.Fields(m[2]), "")
+		placed := pageImage{
+			axisAligned: matrix[1] == 0 && matrix[2] == 0 && matrix[0] > 0 && matrix[3] > 0,
+		}
+
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err == nil {
+			placed.img, _, err = image.Decode(bytes.NewReader(data))
+		}
+		if err != nil {
+			slog.Debug("parsePageImages: decode image", "error", err)
+			images = append(images, placed)
+			continue
+		}
+
+		// The element is laid out at its pixel size and transformed about its centre
+		w, h := float64(placed.img.Bounds().Dx()), float64(placed.img.Bounds().Dy())
+		placed.width = matrix[0] * w / cssPixelsPerPoint
+		placed.height = matrix[3] * h / cssPixelsPerPoint
+		placed.left = (matrix[4] + w/2*(1-matrix[0])) / cssPixelsPerPoint
+		placed.top = (matrix[5] + h/2*(1-matrix[3])) / cssPixelsPerPoint
+
+		images = append(images, placed)
+	}
+
+	return images
+}
+
+// pageLinks returns the URIs of the links on a page
+func pageLinks(doc *fitz.Document, pageNum int) ([]string, error) {
+	links, err := doc.Links(pageNum)
+	if err != nil {
+		return nil, err
+	}
+
+	var uris []string
+	for _, link := range links {
+		if link.URI != "" {
+			uris = append(uris, link.URI)
+		}
+	}
+	return uris, nil
+}
@@ -0,0 +1,157 @@
+package tifpdf2png
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeScannedTestPdf writes a two page PDF around a 40x60 gray image: drawn over the
+// whole of page 1, which also carries a link, and as a small illustration on page 2
+func writeScannedTestPdf(t *testing.T, destPath string) {
+	t.Helper()
+
+	pixels := strings.Repeat("\x80", 40*60)
+	writeRawTestPdf(t, destPath, []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 5 0 R /Resources << /XObject << /Im1 7 0 R >> >> /Annots [8 0 R] >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 6 0 R /Resources << /XObject << /Im1 7 0 R >> >> >>",
+		testPdfStream("", "q 612 0 0 792 0 0 cm /Im1 Do Q"),
+		testPdfStream("", "q 100 0 0 150 72 500 cm /Im1 Do Q"),
+		testPdfStream("/Type /XObject /Subtype /Image /Width 40 /Height 60 /ColorSpace /DeviceGray /BitsPerComponent 8", pixels),
+		"<< /Type /Annot /Subtype /Link /Rect [0 0 100 100] /A << /S /URI /URI (https://example.com/) >> >>",
+	})
+}
+
+func TestParsePageImages(t *testing.T) {
+	// A 1x1 PNG scaled to 72x36pt (96x48 CSS pixels), placed 10pt from the left and 20pt from the top
+	pageHTML := `<div id="page0" style="width:200.0pt;height:100.0pt">
+<img style="position:absolute;transform:matrix(96,0,-0,48,60.833333,50.166667)" src="data:image/png;base64,
+iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAAAAAA6fptVAAAACklEQVR4nGNgAAAAAgABSK+kcQAAAABJRU5ErkJggg==">
+</div>`
+
+	images := parsePageImages(pageHTML)
+	if len(images) != 1 {
+		t.Fatalf("Expected 1 image, got %d", len(images))
+	}
+
+	placed := images[0]
+	if placed.img == nil {
+		t.Fatal("Expected decoded image, got nil")
+	}
+	if !placed.axisAligned {
+		t.Error("Expected axis aligned image")
+	}
+
+	for name, got := range map[string][2]float64{
+		"left":   {placed.left, 10},
+		"top":    {placed.top, 20},
+		"width":  {placed.width, 72},
+		"height": {placed.height, 36},
+	} {
+		if got[0] < got[1]-0.01 || got[0] > got[1]+0.01 {
+			t.Errorf("Expected %s %.2f, got %.2f", name, got[1], got[0])
+		}
+	}
+}
+
+func TestConvertPdfWithFullPageImageAndLinks(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "scanned.pdf")
+	writeScannedTestPdf(t, pdfPath)
+
+	details, err := ConvertPdfToPngWithOptions(pdfPath, dir, "scanned-", &Options{
+		ExtractFullPageImage: true,
+		ExtractLinks:         true,
+	})
+	if err != nil {
+		t.Fatalf("ConvertPdfToPngWithOptions failed: %v", err)
+	}
+	if len(details) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(details))
+	}
+
+	scanned := details[0]
+	if !scanned.Extracted {
+		t.Error("Page 1: expected full-page image to be extracted")
+	}
+	if scanned.Width != 40 || scanned.Height != 60 {
+		t.Errorf("Page 1: expected native size 40x60, got %dx%d", scanned.Width, scanned.Height)
+	}
+	if len(scanned.Links) != 1 || scanned.Links[0] != "https://example.com/" {
+		t.Errorf("Page 1: expected link https://example.com/, got %v", scanned.Links)
+	}
+
+	illustrated := details[1]
+	if illustrated.Extracted {
+		t.Error("Page 2: expected partial-page image to be rendered")
+	}
+	if len(illustrated.Links) != 0 {
+		t.Errorf("Page 2: expected no links, got %v", illustrated.Links)
+	}
+
+	// Without the option the scanned page is rendered at the default resolution
+	details, err = ConvertPdfToPngWithOptions(pdfPath, dir, "rendered-", nil)
+	if err != nil {
+		t.Fatalf("ConvertPdfToPngWithOptions failed: %v", err)
+	}
+	if details[0].Extracted || details[0].Width != int(8.5*pdfRenderDPI) {
+		t.Errorf("Expected rendered page 1 of width %d, got %d (extracted %v)", int(8.5*pdfRenderDPI), details[0].Width, details[0].Extracted)
+	}
+	if details[0].Links != nil {
+		t.Errorf("Expected no links without ExtractLinks, got %v", details[0].Links)
+	}
+}
+
+func TestConvertPdfWithOverlaidFullPageImage(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "overlaid.pdf")
+
+	// The same full-page scan under visible text, invisible OCR text, a filled box and a clip
+	overlays := []string{
+		"BT /F1 12 Tf 72 72 Td (Bates 000123) Tj ET",
+		"BT /F1 12 Tf 3 Tr 72 72 Td (Scanned words) Tj ET",
+		"0 0 0 rg 100 100 200 20 re f",
+		"",
+	}
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R 4 0 R 5 0 R 6 0 R] /Count 4 /MediaBox [0 0 612 792] >>",
+	}
+	for i := range overlays {
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R /Resources << /XObject << /Im1 11 0 R >> /Font << /F1 12 0 R >> >> >>", 7+i))
+	}
+	for i, overlay := range overlays {
+		content := "q 612 0 0 792 0 0 cm /Im1 Do Q " + overlay
+		if i == 3 {
+			content = "q 0 0 612 792 re W n 612 0 0 792 0 0 cm /Im1 Do Q"
+		}
+		objects = append(objects, testPdfStream("", content))
+	}
+	objects = append(objects,
+		testPdfStream("/Type /XObject /Subtype /Image /Width 40 /Height 60 /ColorSpace /DeviceGray /BitsPerComponent 8", strings.Repeat("\x80", 40*60)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+	writeRawTestPdf(t, pdfPath, objects)
+
+	details, err := ConvertPdfToPngWithOptions(pdfPath, dir, "overlaid-", &Options{ExtractFullPageImage: true, ExtractText: true})
+	if err != nil {
+		t.Fatalf("ConvertPdfToPngWithOptions failed: %v", err)
+	}
+	if len(details) != 4 {
+		t.Fatalf("Expected 4 pages, got %d", len(details))
+	}
+	for i, want := range []bool{false, true, false, true} {
+		if details[i].Extracted != want {
+			t.Errorf("Page %d: expected extracted %v, got %v", i+1, want, details[i].Extracted)
+		}
+	}
+	if details[0].Width == 40 {
+		t.Error("Page 1: expected the stamped page to be rendered, got the scan's native size")
+	}
+	if text := details[1].Text; text == nil || !strings.Contains(text.Content, "Scanned words") {
+		t.Errorf("Page 2: expected the OCR text layer to be kept, got %+v", text)
+	}
+}
@@ -0,0 +1,213 @@
+package tifpdf2png
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tiff "github.com/dhushon/tiff"
+)
+
+// GeoKey IDs with a special meaning for the conversion (GeoTIFF 1.1, section 7)
+const (
+	geoKeyRasterType          = 1025
+	geoKeyGeographicType      = 2048
+	geoKeyProjectedCSType     = 3072
+	geoRasterPixelIsPoint     = 2
+	geoKeyDirectoryHeaderSize = 4
+)
+
+// geoKeyNames maps common GeoKey IDs to their names
+var geoKeyNames = map[int]string{
+	1024: "GTModelTypeGeoKey",
+	1025: "GTRasterTypeGeoKey",
+	1026: "GTCitationGeoKey",
+	2048: "GeographicTypeGeoKey",
+	2049: "GeogCitationGeoKey",
+	2050: "GeogGeodeticDatumGeoKey",
+	2051: "GeogPrimeMeridianGeoKey",
+	2052: "GeogLinearUnitsGeoKey",
+	2054: "GeogAngularUnitsGeoKey",
+	2056: "GeogEllipsoidGeoKey",
+	2057: "GeogSemiMajorAxisGeoKey",
+	2058: "GeogSemiMinorAxisGeoKey",
+	2059: "GeogInvFlatteningGeoKey",
+	3072: "ProjectedCSTypeGeoKey",
+	3073: "PCSCitationGeoKey",
+	3074: "ProjectionGeoKey",
+	3075: "ProjCoordTransGeoKey",
+	3076: "ProjLinearUnitsGeoKey",
+	4096: "VerticalCSTypeGeoKey",
+	4097: "VerticalCitationGeoKey",
+	4098: "VerticalDatumGeoKey",
+	4099: "VerticalUnitsGeoKey",
+}
+
+// readGeoInfo decodes the georeferencing tags of a TIFF image, returning nil for
+// images that are not GeoTIFFs. The geotransform describes the source pixels.
+func readGeoInfo(ifd *tiff.IFD) *GeoInfo {
+	floats := func(tag tiff.TagType) []float64 {
+		if entry, ok := ifd.EntryMap[tag]; ok {
+			return entry.GetFloats()
+		}
+		return nil
+	}
+
+	pixelScale := floats(tiff.TagType_ModelPixelScaleTag)
+	tiepoints := floats(tiff.TagType_ModelTiepointTag)
+	transformation := floats(tiff.TagType_ModelTransformationTag)
+	if len(tiepoints) < 6 && len(transformation) < 16 {
+		return nil
+	}
+
+	geo := &GeoInfo{Keys: readGeoKeys(ifd)}
+	for i := 0; i+6 <= len(tiepoints); i += 6 {
+		geo.Tiepoints = append(geo.Tiepoints, [6]float64(tiepoints[i:i+6]))
+	}
+	if len(pixelScale) >= 3 {
+		geo.PixelScale = pixelScale[:3]
+	}
+	if len(transformation) >= 16 {
+		geo.ModelTransformation = transformation[:16]
+	}
+
+	switch {
+	case len(geo.ModelTransformation) == 16:
+		m := geo.ModelTransformation
+		geo.GeoTransform = []float64{m[3], m[0], m[1], m[7], m[4], m[5]}
+	case len(geo.Tiepoints) > 0 && len(geo.PixelScale) == 3 && geo.PixelScale[0] != 0:
+		tp, scale := geo.Tiepoints[0], geo.PixelScale
+		geo.GeoTransform = []float64{tp[3] - tp[0]*scale[0], scale[0], 0, tp[4] + tp[1]*scale[1], 0, -scale[1]}
+	default:
+		// Multiple tiepoints without a pixel scale describe a warp, which has no affine transform
+		return geo
+	}
+
+	// Transforms refer to pixel corners, so shift PixelIsPoint rasters by half a pixel
+	if rasterType, ok := geo.Keys[geoKeyName(geoKeyRasterType)].(int); ok && rasterType == geoRasterPixelIsPoint {
+		gt := geo.GeoTransform
+		gt[0] -= 0.5*gt[1] + 0.5*gt[2]
+		gt[3] -= 0.5*gt[4] + 0.5*gt[5]
+	}
+
+	if code, ok := geo.Keys[geoKeyName(geoKeyProjectedCSType)].(int); ok && code > 0 && code < 32767 {
+		geo.EPSG = code
+	} else if code, ok := geo.Keys[geoKeyName(geoKeyGeographicType)].(int); ok && code > 0 && code < 32767 {
+		geo.EPSG = code
+	}
+
+	return geo
+}
+
+// readGeoKeys decodes the GeoKey directory, resolving values stored in the double and ASCII parameter tags
+func readGeoKeys(ifd *tiff.IFD) map[string]any {
+	entry, ok := ifd.EntryMap[tiff.TagType_GeoKeyDirectoryTag]
+	if !ok {
+		return nil
+	}
+	directory := entry.GetInts()
+	if len(directory) < geoKeyDirectoryHeaderSize {
+		return nil
+	}
+
+	var doubles []float64
+	if entry, ok := ifd.EntryMap[tiff.TagType_GeoDoubleParamsTag]; ok {
+		doubles = entry.GetFloats()
+	}
+	var ascii string
+	if entry, ok := ifd.EntryMap[tiff.TagType_GeoAsciiParamsTag]; ok {
+		ascii = entry.GetString()
+	}
+
+	keys := make(map[string]any)
+	numberOfKeys := int(directory[3])
+	for k := 0; k < numberOfKeys; k++ {
+		i := geoKeyDirectoryHeaderSize + k*4
+		if i+4 > len(directory) {
+			break
+		}
+		id, location, count, offset := int(directory[i]), directory[i+1], int(directory[i+2]), int(directory[i+3])
+
+		switch tiff.TagType(location) {
+		case 0:
+			keys[geoKeyName(id)] = offset
+		case tiff.TagType_GeoDoubleParamsTag:
+			if offset >= 0 && offset+count <= len(doubles) {
+				if count == 1 {
+					keys[geoKeyName(id)] = doubles[offset]
+				} else {
+					keys[geoKeyName(id)] = doubles[offset : offset+count]
+				}
+			}
+		case tiff.TagType_GeoAsciiParamsTag:
+			if offset >= 0 && offset+count <= len(ascii) {
+				keys[geoKeyName(id)] = strings.TrimRight(ascii[offset:offset+count], "|\x00")
+			}
+		case tiff.TagType_GeoKeyDirectoryTag:
+			if offset >= 0 && offset+count <= len(directory) {
+				values := make([]int, count)
+				for n := range values {
+					values[n] = int(directory[offset+n])
+				}
+				keys[geoKeyName(id)] = values
+			}
+		}
+	}
+
+	return keys
+}
+
+// geoKeyName returns the name of a GeoKey, or GeoKey<id> for keys without a known name
+func geoKeyName(id int) string {
+	if name, ok := geoKeyNames[id]; ok {
+		return name
+	}
+	return "GeoKey" + strconv.Itoa(id)
+}
+
+// forOutput returns a copy of the georeferencing adjusted for an output image that was
+// scaled from the source pixels by scaleX and scaleY and then cropped
+func (geo *GeoInfo) forOutput(scaleX, scaleY float64, cropInfo CropInfo) *GeoInfo {
+	out := *geo
+	if len(geo.GeoTransform) != 6 {
+		return &out
+	}
+	gt := append([]float64(nil), geo.GeoTransform...)
+
+	gt[1], gt[4] = gt[1]/scaleX, gt[4]/scaleX
+	gt[2], gt[5] = gt[2]/scaleY, gt[5]/scaleY
+
+	x, y := float64(cropInfo.OffsetX), float64(cropInfo.OffsetY)
+	gt[0] += x*gt[1] + y*gt[2]
+	gt[3] += x*gt[4] + y*gt[5]
+
+	out.GeoTransform = gt
+	return &out
+}
+
+// writeWorldFile writes an ESRI world file (.pgw) next to a PNG and returns its URL
+func writeWorldFile(sink PageSink, imageName string, geo *GeoInfo) (string, error) {
+	gt := geo.GeoTransform
+	if len(gt) != 6 {
+		return "", fmt.Errorf("no affine geotransform for %s", imageName)
+	}
+
+	worldName := strings.TrimSuffix(imageName, ".png") + ".pgw"
+
+	// World files locate the centre of the upper left pixel
+	lines := []float64{
+		gt[1],
+		gt[4],
+		gt[2],
+		gt[5],
+		gt[0] + 0.5*gt[1] + 0.5*gt[2],
+		gt[3] + 0.5*gt[4] + 0.5*gt[5],
+	}
+	var b strings.Builder
+	for _, v := range lines {
+		b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		b.WriteString("\n")
+	}
+
+	return writeSinkFile(sink, worldName, []byte(b.String()))
+}
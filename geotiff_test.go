@@ -0,0 +1,112 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertGeoTiff(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "ortho.tif")
+
+	// A 40x20 UTM zone 11N raster with 30m pixels
+	pixels := bytes.Repeat([]byte{0x80}, 40*20)
+	writeRawTestTiff(t, tiffPath, []testTiffPage{{
+		width: 40, height: 20, pixels: pixels,
+		tags: map[uint16]testTiffTag{
+			33550: {tiffDouble, []float64{30, 30, 0}},
+			33922: {tiffDouble, []float64{0, 0, 0, 440720, 3751320, 0}},
+			34735: {tiffShort, []uint16{1, 1, 0, 3, 1024, 0, 1, 1, 1025, 0, 1, 1, 3072, 0, 1, 26711}},
+		},
+	}})
+
+	details, err := ConvertTiffToPngWithOptions(tiffPath, dir, "ortho-", &Options{WriteWorldFile: true})
+	if err != nil {
+		t.Fatalf("ConvertTiffToPngWithOptions failed: %v", err)
+	}
+
+	detail := details[0]
+	geo := detail.Geo
+	if geo == nil {
+		t.Fatal("Expected georeferencing, got nil")
+	}
+	if geo.EPSG != 26711 {
+		t.Errorf("Expected EPSG 26711, got %d", geo.EPSG)
+	}
+	if geo.Keys["GTModelTypeGeoKey"] != 1 {
+		t.Errorf("Expected GTModelTypeGeoKey 1, got %v", geo.Keys["GTModelTypeGeoKey"])
+	}
+
+	expected := []float64{440720, 30, 0, 3751320, 0, -30}
+	for i := range expected {
+		if len(geo.GeoTransform) != 6 || geo.GeoTransform[i] != expected[i] {
+			t.Fatalf("Expected geotransform %v, got %v", expected, geo.GeoTransform)
+		}
+	}
+
+	if geo.WorldFile != filepath.Join(dir, "ortho-0.pgw") {
+		t.Errorf("Expected world file ortho-0.pgw, got %s", geo.WorldFile)
+	}
+	data, err := os.ReadFile(geo.WorldFile)
+	if err != nil {
+		t.Fatalf("Failed to read world file: %v", err)
+	}
+	// The world file locates the centre of the upper left pixel
+	if string(data) != "30\n0\n0\n-30\n440735\n3751305\n" {
+		t.Errorf("Unexpected world file contents %q", data)
+	}
+}
+
+func TestConvertGeoTiffWithoutGeoTransform(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "warp.tif")
+
+	// Four tiepoints without a pixel scale describe a warp
+	writeRawTestTiff(t, tiffPath, []testTiffPage{{
+		width: 40, height: 20, pixels: bytes.Repeat([]byte{0x80}, 40*20),
+		tags: map[uint16]testTiffTag{
+			33922: {tiffDouble, []float64{
+				0, 0, 0, 440720, 3751320, 0,
+				40, 0, 0, 441920, 3751330, 0,
+				0, 20, 0, 440710, 3750720, 0,
+				40, 20, 0, 441910, 3750730, 0,
+			}},
+		},
+	}})
+
+	details, err := ConvertTiffToPngWithOptions(tiffPath, dir, "warp-", &Options{WriteWorldFile: true})
+	if err != nil {
+		t.Fatalf("Expected the page to convert without a world file, got %v", err)
+	}
+	geo := details[0].Geo
+	if geo == nil || len(geo.Tiepoints) != 4 {
+		t.Fatalf("Expected 4 tiepoints, got %+v", geo)
+	}
+	if geo.GeoTransform != nil || geo.WorldFile != "" {
+		t.Errorf("Expected no geotransform or world file, got %v %q", geo.GeoTransform, geo.WorldFile)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "warp-0.pgw")); !os.IsNotExist(err) {
+		t.Errorf("Expected no world file on disk, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "warp-0.png")); err != nil {
+		t.Errorf("Expected the page to be written: %v", err)
+	}
+}
+
+func TestGeoInfoForOutput(t *testing.T) {
+	geo := &GeoInfo{GeoTransform: []float64{1000, 10, 0, 2000, 0, -10}}
+
+	// Resampled to twice the resolution, then cropped by 4 pixels on the left and 6 at the top
+	out := geo.forOutput(2, 2, CropInfo{OffsetX: 4, OffsetY: 6})
+	expected := []float64{1020, 5, 0, 1970, 0, -5}
+	for i := range expected {
+		if out.GeoTransform[i] != expected[i] {
+			t.Fatalf("Expected geotransform %v, got %v", expected, out.GeoTransform)
+		}
+	}
+	if geo.GeoTransform[0] != 1000 {
+		t.Errorf("Expected source geotransform to be unchanged, got %v", geo.GeoTransform)
+	}
+}
@@ -0,0 +1,136 @@
+package grpcserver
+
+import (
+	"encoding/json"
+	"log/slog"
+
+	"github.com/dhushon/go-tifpdf2png"
+	pb "github.com/dhushon/go-tifpdf2png/proto/tifpdf2png/v1"
+)
+
+// imageDetailToProto converts an ImageDetail to its message
+func imageDetailToProto(d *tifpdf2png.ImageDetail) *pb.ImageDetail {
+	msg := &pb.ImageDetail{
+		ActualType:   d.ActualType,
+		SourceFormat: d.SourceFormat,
+		Page:         int32(d.Page),
+		Pages:        int32(d.Pages),
+		SubImage:     int32(d.SubImage),
+		Url:          d.URL,
+		Width:        int32(d.Width),
+		Height:       int32(d.Height),
+		Format:       d.Format,
+		Quality:      d.Quality,
+		Links:        d.Links,
+		Extracted:    d.Extracted,
+		SourceDpi:    resolutionToProto(d.SourceDPI),
+		Dpi:          resolutionToProto(d.DPI),
+		Transforms:   d.Transforms,
+	}
+	if c := d.CropDetail; c != nil {
+		msg.CropDetail = &pb.CropDetail{
+			OffsetX:        int32(c.OffsetX),
+			OffsetY:        int32(c.OffsetY),
+			OriginalWidth:  int32(c.OriginalWidth),
+			OriginalHeight: int32(c.OriginalHeight),
+			CroppedWidth:   int32(c.CroppedWidth),
+			CroppedHeight:  int32(c.CroppedHeight),
+		}
+	}
+	if t := d.Text; t != nil {
+		msg.Text = &pb.PageText{Content: t.Content, Html: t.HTML}
+		for _, w := range t.Words {
+			msg.Text.Words = append(msg.Text.Words, &pb.WordBox{Text: w.Text, X: int32(w.X), Y: int32(w.Y), Width: int32(w.Width), Height: int32(w.Height), Estimated: w.Estimated})
+		}
+	}
+	for _, a := range d.Annotations {
+		msg.Annotations = append(msg.Annotations, &pb.Annotation{
+			Type:       a.Type,
+			X:          int32(a.X),
+			Y:          int32(a.Y),
+			Width:      int32(a.Width),
+			Height:     int32(a.Height),
+			Contents:   a.Contents,
+			FieldName:  a.FieldName,
+			FieldType:  a.FieldType,
+			FieldValue: a.FieldValue,
+			Hidden:     a.Hidden,
+			Rendered:   a.Rendered,
+		})
+	}
+	if t := d.Tiff; t != nil {
+		msg.Tiff = &pb.TiffPageInfo{
+			Compression:    t.Compression,
+			Photometric:    t.Photometric,
+			XResolution:    t.XResolution,
+			YResolution:    t.YResolution,
+			ResolutionUnit: t.ResolutionUnit,
+			DateTime:       t.DateTime,
+			Software:       t.Software,
+			Make:           t.Make,
+			Model:          t.Model,
+			PageName:       t.PageName,
+			Orientation:    int32(t.Orientation),
+			IccProfile:     t.ICCProfile,
+		}
+	}
+	if g := d.Geo; g != nil {
+		msg.Geo = &pb.GeoInfo{
+			Epsg:                int32(g.EPSG),
+			PixelScale:          g.PixelScale,
+			ModelTransformation: g.ModelTransformation,
+			GeoTransform:        g.GeoTransform,
+		}
+		for _, tp := range g.Tiepoints {
+			msg.Geo.Tiepoints = append(msg.Geo.Tiepoints, &pb.Tiepoint{Values: tp[:]})
+		}
+		if len(g.Keys) > 0 {
+			keys, err := json.Marshal(g.Keys)
+			if err != nil {
+				slog.Warn("ConverterService: encode GeoKeys", "error", err)
+			}
+			msg.Geo.KeysJson = string(keys)
+		}
+	}
+	return msg
+}
+
+// resolutionToProto converts a Resolution to its message
+func resolutionToProto(r *tifpdf2png.Resolution) *pb.Resolution {
+	if r == nil {
+		return nil
+	}
+	return &pb.Resolution{X: r.X, Y: r.Y}
+}
+
+// documentInfoToProto converts a DocumentInfo to its message
+func documentInfoToProto(info *tifpdf2png.DocumentInfo) *pb.DocumentInfo {
+	if info == nil {
+		return nil
+	}
+	msg := &pb.DocumentInfo{
+		Format:       info.Format,
+		Encryption:   info.Encryption,
+		Title:        info.Title,
+		Author:       info.Author,
+		Subject:      info.Subject,
+		Keywords:     info.Keywords,
+		Creator:      info.Creator,
+		Producer:     info.Producer,
+		CreationDate: info.CreationDate,
+		ModDate:      info.ModDate,
+		Pages:        int32(info.Pages),
+		Reflowable:   info.Reflowable,
+		PageLabels:   info.PageLabels,
+	}
+	for _, size := range info.PageSizes {
+		msg.PageSizes = append(msg.PageSizes, &pb.PageSize{Page: int32(size.Page), Width: size.Width, Height: size.Height})
+	}
+	for _, entry := range info.Outline {
+		msg.Outline = append(msg.Outline, &pb.OutlineEntry{Level: int32(entry.Level), Title: entry.Title, Page: int32(entry.Page), Uri: entry.URI})
+	}
+	for _, field := range info.FormFields {
+		msg.FormFields = append(msg.FormFields, &pb.FormField{Name: field.Name, Type: field.Type, Value: field.Value, Page: int32(field.Page)})
+	}
+	return msg
+}
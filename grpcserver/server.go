@@ -0,0 +1,220 @@
+// Package grpcserver implements the tifpdf2png.v1.ConverterService gRPC service on top
+// of the tifpdf2png library.
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/dhushon/go-tifpdf2png"
+	pb "github.com/dhushon/go-tifpdf2png/proto/tifpdf2png/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultMaxDPI is the highest dpi a request may ask for when Config.MaxDPI is not set
+const defaultMaxDPI = 600
+
+// Config configures the Server returned by NewServer
+type Config struct {
+	Options *tifpdf2png.Options // Conversion options applied to every request; nil means the defaults
+	MaxDPI  float64             // Highest dpi a request may ask for; 0 means 600
+}
+
+// Server converts the documents of ConverterService requests, returning the pages in
+// the responses rather than writing them to disk
+type Server struct {
+	pb.UnimplementedConverterServiceServer
+
+	cfg Config
+}
+
+// NewServer returns a server applying cfg.Options to every request, with the fields of
+// each request's ConvertOptions that are set taking precedence. Requests asking for a
+// dpi above cfg.MaxDPI or a threshold above 255 fail with InvalidArgument. A nil cfg
+// means the defaults.
+func NewServer(cfg *Config) *Server {
+	s := &Server{}
+	if cfg != nil {
+		s.cfg = *cfg
+	}
+	if s.cfg.MaxDPI <= 0 {
+		s.cfg.MaxDPI = defaultMaxDPI
+	}
+	return s
+}
+
+// MessageSizeOptions returns the grpc.ServerOptions raising the largest request and
+// response to limit bytes from the default of 4 MiB. A unary response holds every page
+// of a document, so documents whose pages exceed the limit together need ConvertStream.
+func MessageSizeOptions(limit int) []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.MaxRecvMsgSize(limit), grpc.MaxSendMsgSize(limit)}
+}
+
+// Convert implements pb.ConverterServiceServer
+func (s *Server) Convert(ctx context.Context, req *pb.ConvertRequest) (*pb.ConvertResponse, error) {
+	resp := &pb.ConvertResponse{}
+	document, err := s.convert(ctx, req, func(page *pb.Page) error {
+		resp.Pages = append(resp.Pages, page)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp.Document = document
+	return resp, nil
+}
+
+// ConvertStream implements pb.ConverterServiceServer
+func (s *Server) ConvertStream(req *pb.ConvertRequest, stream pb.ConverterService_ConvertStreamServer) error {
+	document, err := s.convert(stream.Context(), req, func(page *pb.Page) error {
+		return stream.Send(&pb.ConvertStreamResponse{Result: &pb.ConvertStreamResponse_Page{Page: page}})
+	})
+	if err != nil {
+		return err
+	}
+	if document == nil {
+		return nil
+	}
+	return stream.Send(&pb.ConvertStreamResponse{Result: &pb.ConvertStreamResponse_Document{Document: document}})
+}
+
+// convert converts the document of a request, passing each page to send as soon as it is
+// saved, and returns the document info when it was requested. The conversion is stopped
+// between pages when ctx is done.
+func (s *Server) convert(ctx context.Context, req *pb.ConvertRequest, send func(*pb.Page) error) (*pb.DocumentInfo, error) {
+	if len(req.GetDocument()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "document is empty")
+	}
+
+	opts, err := requestOptions(&s.cfg, req.GetOptions())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	files := &pageFiles{files: make(map[string][]byte)}
+	opts.Sink = files
+	callerOpts := *opts
+	opts.OnPage = func(detail *tifpdf2png.ImageDetail) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if callerOpts.OnPage != nil {
+			if err := callerOpts.OnPage(detail); err != nil {
+				return err
+			}
+		}
+		return send(&pb.Page{Detail: imageDetailToProto(detail), Data: files.take(detail.URL)})
+	}
+
+	_, info, err := tifpdf2png.ConvertReaderWithDocumentInfo(bytes.NewReader(req.GetDocument()), "", "page-", opts)
+	if err != nil {
+		slog.Warn("ConverterService: conversion failed", "error", err)
+		return nil, statusFromError(err)
+	}
+	if !req.GetDocumentInfo() {
+		return nil, nil
+	}
+	return documentInfoToProto(info), nil
+}
+
+// requestOptions returns the server's options with the fields set in a request's
+// ConvertOptions. World files and manifests are left out, as responses only carry pages.
+func requestOptions(cfg *Config, o *pb.ConvertOptions) (*tifpdf2png.Options, error) {
+	opts := tifpdf2png.Options{}
+	if cfg.Options != nil {
+		opts = *cfg.Options
+	}
+	opts.WriteWorldFile = false
+	opts.WriteManifest = false
+	if o == nil {
+		return &opts, nil
+	}
+	if o.Dpi < 0 || o.Dpi > cfg.MaxDPI {
+		return nil, fmt.Errorf("dpi %g is outside 0 to %g", o.Dpi, cfg.MaxDPI)
+	}
+	if o.Threshold > 255 {
+		return nil, fmt.Errorf("threshold %d is above 255", o.Threshold)
+	}
+	if o.Format != "" {
+		opts.Format = tifpdf2png.OutputFormat(o.Format)
+	}
+	if o.Dpi != 0 {
+		opts.DPI = o.Dpi
+	}
+	if o.Pages != "" {
+		opts.Pages = tifpdf2png.PageSelection(o.Pages)
+	}
+	if o.Threshold != 0 {
+		opts.Threshold = uint8(o.Threshold)
+	}
+	if o.Password != "" {
+		opts.Password = o.Password
+	}
+	opts.NoCrop = opts.NoCrop || o.NoCrop
+	opts.NoBinarize = opts.NoBinarize || o.NoBinarize
+	opts.ExtractText = opts.ExtractText || o.ExtractText
+	opts.ExtractLinks = opts.ExtractLinks || o.ExtractLinks
+	opts.ExtractAnnotations = opts.ExtractAnnotations || o.ExtractAnnotations
+	return &opts, nil
+}
+
+// statusFromError returns the gRPC status of a conversion error
+func statusFromError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, tifpdf2png.ErrUnsupportedFormat):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, tifpdf2png.ErrEncrypted), errors.Is(err, tifpdf2png.ErrBadPassword):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case status.Code(err) != codes.Unknown:
+		// Errors sending a page are already gRPC statuses
+		return err
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// pageFiles is a tifpdf2png.PageSink keeping the output files in memory until they are
+// sent
+type pageFiles struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+// Create implements tifpdf2png.PageSink
+func (p *pageFiles) Create(name string) (io.WriteCloser, string, error) {
+	return &pageFile{files: p, name: name}, name, nil
+}
+
+// take removes and returns the contents of an output file
+func (p *pageFiles) take(name string) []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data := p.files[name]
+	delete(p.files, name)
+	return data
+}
+
+// pageFile buffers an output file until it is stored on Close
+type pageFile struct {
+	bytes.Buffer
+	files *pageFiles
+	name  string
+}
+
+// Close implements io.Closer
+func (f *pageFile) Close() error {
+	f.files.mu.Lock()
+	defer f.files.mu.Unlock()
+	f.files.files[f.name] = f.Bytes()
+	return nil
+}
@@ -0,0 +1,222 @@
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/dhushon/go-tifpdf2png"
+	pb "github.com/dhushon/go-tifpdf2png/proto/tifpdf2png/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestClient serves a Server with cfg over an in-process connection and returns a
+// client for it
+func newTestClient(t *testing.T, cfg *Config, serverOpts ...grpc.ServerOption) pb.ConverterServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(serverOpts...)
+	pb.RegisterConverterServiceServer(server, NewServer(cfg))
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect to server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewConverterServiceClient(conn)
+}
+
+// testPdf returns a minimal PDF with one Letter-sized page per entry in pageTexts
+func testPdf(pageTexts []string) []byte {
+	return paddedTestPdf(pageTexts, 0)
+}
+
+// paddedTestPdf returns testPdf with about padding bytes of comments after the header
+func paddedTestPdf(pageTexts []string, padding int) []byte {
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R >>", "", "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"}
+	var kids []string
+	for _, text := range pageTexts {
+		content := fmt.Sprintf("BT /F1 24 Tf 72 696 Td (%s) Tj ET", text)
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)+1))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R /Resources << /Font << /F1 3 0 R >> >> >>", len(objects)+2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pageTexts))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	for range padding / 1024 {
+		b.WriteString("%" + strings.Repeat("x", 1022) + "\n")
+	}
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+func TestConvert(t *testing.T) {
+	client := newTestClient(t, nil)
+	req := &pb.ConvertRequest{
+		Document:     testPdf([]string{"One", "Two", "Three"}),
+		Options:      &pb.ConvertOptions{Pages: "2-", Dpi: 72, ExtractText: true},
+		DocumentInfo: true,
+	}
+
+	resp, err := client.Convert(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if len(resp.Pages) != 2 || resp.Document.GetPages() != 3 {
+		t.Fatalf("Expected pages 2-3 of 3, got %d pages and %v", len(resp.Pages), resp.Document)
+	}
+	detail := resp.Pages[0].Detail
+	if detail.Page != 2 || detail.Url != "page-1.png" || !strings.Contains(detail.Text.GetContent(), "Two") {
+		t.Errorf("Unexpected page detail %v", detail)
+	}
+	img, err := png.Decode(bytes.NewReader(resp.Pages[0].Data))
+	if err != nil {
+		t.Fatalf("Page data is not a PNG: %v", err)
+	}
+	if img.Bounds().Dx() != int(detail.Width) || img.Bounds().Dy() != int(detail.Height) {
+		t.Errorf("Page data is %v, detail says %dx%d", img.Bounds(), detail.Width, detail.Height)
+	}
+
+	// Errors are mapped to status codes
+	for _, tc := range []struct {
+		req  *pb.ConvertRequest
+		code codes.Code
+	}{
+		{&pb.ConvertRequest{}, codes.InvalidArgument},
+		{&pb.ConvertRequest{Document: []byte("plain text notes")}, codes.InvalidArgument},
+		{&pb.ConvertRequest{Document: req.Document, Options: &pb.ConvertOptions{Pages: "x"}}, codes.InvalidArgument},
+		{&pb.ConvertRequest{Document: req.Document, Options: &pb.ConvertOptions{Dpi: 601}}, codes.InvalidArgument},
+		{&pb.ConvertRequest{Document: req.Document, Options: &pb.ConvertOptions{Dpi: 1e5}}, codes.InvalidArgument},
+		{&pb.ConvertRequest{Document: req.Document, Options: &pb.ConvertOptions{Dpi: -72}}, codes.InvalidArgument},
+		{&pb.ConvertRequest{Document: req.Document, Options: &pb.ConvertOptions{Threshold: 256}}, codes.InvalidArgument},
+	} {
+		if _, err := client.Convert(context.Background(), tc.req); status.Code(err) != tc.code {
+			t.Errorf("Expected %v, got %v", tc.code, err)
+		}
+	}
+}
+
+func TestConvertStream(t *testing.T) {
+	client := newTestClient(t, nil)
+	stream, err := client.ConvertStream(context.Background(), &pb.ConvertRequest{
+		Document:     testPdf([]string{"One", "Two"}),
+		Options:      &pb.ConvertOptions{Dpi: 72},
+		DocumentInfo: true,
+	})
+	if err != nil {
+		t.Fatalf("ConvertStream failed: %v", err)
+	}
+
+	// Pages arrive in order, followed by the document info
+	var pages []int32
+	var document *pb.DocumentInfo
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		if document != nil {
+			t.Fatal("Received a response after the document info")
+		}
+		if page := resp.GetPage(); page != nil {
+			if len(page.Data) == 0 {
+				t.Errorf("Page %d has no data", page.Detail.Page)
+			}
+			pages = append(pages, page.Detail.Page)
+		}
+		document = resp.GetDocument()
+	}
+	if len(pages) != 2 || pages[0] != 1 || pages[1] != 2 || document.GetPages() != 2 {
+		t.Errorf("Expected pages 1 and 2 then the document info, got %v and %v", pages, document)
+	}
+}
+
+func TestConvertSkipsSideFiles(t *testing.T) {
+	// World files and manifests set in the server's options have no place in a response
+	client := newTestClient(t, &Config{Options: &tifpdf2png.Options{WriteWorldFile: true, WriteManifest: true}})
+	resp, err := client.Convert(context.Background(), &pb.ConvertRequest{
+		Document: testPdf([]string{"One"}),
+		Options:  &pb.ConvertOptions{Dpi: 72},
+	})
+	if err != nil {
+		t.Fatalf("Convert failed: %v", err)
+	}
+	if len(resp.Pages) != 1 || resp.Pages[0].Detail.Url != "page-0.png" {
+		t.Errorf("Expected only page-0.png, got %v", resp.Pages)
+	}
+
+	opts, err := requestOptions(&Config{Options: &tifpdf2png.Options{WriteWorldFile: true, WriteManifest: true}}, nil)
+	if err != nil || opts.WriteWorldFile || opts.WriteManifest {
+		t.Errorf("Expected world files and manifests to be left out, got %+v", opts)
+	}
+}
+
+func TestMessageSizeOptions(t *testing.T) {
+	req := &pb.ConvertRequest{
+		Document: paddedTestPdf([]string{"One"}, 5<<20),
+		Options:  &pb.ConvertOptions{Dpi: 72},
+	}
+	send := grpc.MaxCallSendMsgSize(16 << 20)
+
+	// Requests above the default 4 MiB are refused
+	client := newTestClient(t, nil)
+	if _, err := client.Convert(context.Background(), req, send); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("Expected ResourceExhausted, got %v", err)
+	}
+
+	client = newTestClient(t, nil, MessageSizeOptions(16<<20)...)
+	resp, err := client.Convert(context.Background(), req, send)
+	if err != nil {
+		t.Fatalf("Convert failed with raised limits: %v", err)
+	}
+	if len(resp.Pages) != 1 || len(resp.Pages[0].Data) == 0 {
+		t.Errorf("Expected one page, got %v", resp.Pages)
+	}
+}
+
+func TestConvertMaxDPI(t *testing.T) {
+	client := newTestClient(t, &Config{MaxDPI: 100})
+	req := &pb.ConvertRequest{Document: testPdf([]string{"One"}), Options: &pb.ConvertOptions{Dpi: 150}}
+	if _, err := client.Convert(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument above the configured maximum, got %v", err)
+	}
+
+	req.Options.Dpi = 100
+	resp, err := client.Convert(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Convert failed at the maximum dpi: %v", err)
+	}
+	if dpi := resp.Pages[0].Detail.Dpi; dpi.GetX() != 100 {
+		t.Errorf("Expected pages at 100 dpi, got %v", dpi)
+	}
+}
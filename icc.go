@@ -0,0 +1,301 @@
+package tifpdf2png
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+// iccHeaderSize is the size of the fixed ICC profile header preceding the tag table
+const iccHeaderSize = 128
+
+// xyzD50ToLinearSRGB converts PCS XYZ (D50) to linear sRGB, including the Bradford
+// adaptation from D50 to the D65 white point of sRGB
+var xyzD50ToLinearSRGB = [3][3]float64{
+	{3.1338561, -1.6168667, -0.4906146},
+	{-0.9787684, 1.9161415, 0.0334540},
+	{0.0719453, -0.2289914, 1.4052427},
+}
+
+// iccProfile is the part of an embedded ICC profile needed to convert matrix/TRC based
+// RGB and gray images to sRGB
+type iccProfile struct {
+	ColorSpace  string // Data colour space: "RGB", "GRAY" or "CMYK"
+	Description string // Profile description
+
+	matrix    [3][3]float64 // Columns are the red, green and blue colorants in PCS XYZ
+	curves    [3]toneCurve  // Red, green and blue (or gray) tone response curves
+	hasMatrix bool
+}
+
+// toneCurve is an ICC curveType or parametricCurveType mapping encoded values to linear light
+type toneCurve struct {
+	table  []uint16  // Sampled curve, if any
+	params []float64 // Parametric curve parameters g, a, b, c, d, e, f
+	kind   int       // Parametric function type (ICC.1:2010, table 65)
+}
+
+// parseICCProfile decodes the header, description, colorants and tone curves of an ICC profile
+func parseICCProfile(data []byte) (*iccProfile, error) {
+	if len(data) < iccHeaderSize+4 || string(data[36:40]) != "acsp" {
+		return nil, fmt.Errorf("not an ICC profile")
+	}
+
+	profile := &iccProfile{ColorSpace: strings.TrimSpace(string(data[16:20]))}
+
+	tags := make(map[string][]byte)
+	count := int(binary.BigEndian.Uint32(data[iccHeaderSize:]))
+	for i := 0; i < count; i++ {
+		entry := iccHeaderSize + 4 + i*12
+		if entry+12 > len(data) {
+			break
+		}
+		offset := int(binary.BigEndian.Uint32(data[entry+4:]))
+		size := int(binary.BigEndian.Uint32(data[entry+8:]))
+		if offset < 0 || size < 8 || offset+size > len(data) {
+			continue
+		}
+		tags[string(data[entry:entry+4])] = data[offset : offset+size]
+	}
+
+	profile.Description = iccText(tags["desc"])
+
+	switch profile.ColorSpace {
+	case "RGB":
+		colorants := [3]string{"rXYZ", "gXYZ", "bXYZ"}
+		trcs := [3]string{"rTRC", "gTRC", "bTRC"}
+		profile.hasMatrix = true
+		for c := 0; c < 3; c++ {
+			xyz, ok := iccXYZ(tags[colorants[c]])
+			curve, curveOK := iccCurve(tags[trcs[c]])
+			if !ok || !curveOK {
+				profile.hasMatrix = false
+				break
+			}
+			for r := 0; r < 3; r++ {
+				profile.matrix[r][c] = xyz[r]
+			}
+			profile.curves[c] = curve
+		}
+	case "GRAY":
+		curve, ok := iccCurve(tags["kTRC"])
+		profile.curves[0] = curve
+		profile.hasMatrix = ok
+	}
+
+	return profile, nil
+}
+
+// iccText decodes a textDescriptionType (ICC v2) or multiLocalizedUnicodeType (ICC v4) tag
+func iccText(tag []byte) string {
+	if len(tag) < 12 {
+		return ""
+	}
+	switch string(tag[:4]) {
+	case "desc":
+		n := int(binary.BigEndian.Uint32(tag[8:]))
+		if 12+n > len(tag) {
+			return ""
+		}
+		return strings.TrimRight(string(tag[12:12+n]), "\x00")
+	case "mluc":
+		if len(tag) < 28 || binary.BigEndian.Uint32(tag[8:]) == 0 {
+			return ""
+		}
+		n := int(binary.BigEndian.Uint32(tag[20:]))
+		offset := int(binary.BigEndian.Uint32(tag[24:]))
+		if offset+n > len(tag) {
+			return ""
+		}
+		units := make([]uint16, n/2)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(tag[offset+2*i:])
+		}
+		return strings.TrimRight(string(utf16.Decode(units)), "\x00")
+	}
+	return ""
+}
+
+// iccXYZ decodes the first value of an XYZType tag
+func iccXYZ(tag []byte) ([3]float64, bool) {
+	var xyz [3]float64
+	if len(tag) < 20 || string(tag[:4]) != "XYZ " {
+		return xyz, false
+	}
+	for i := range xyz {
+		xyz[i] = iccFixed(tag[8+4*i:])
+	}
+	return xyz, true
+}
+
+// iccCurve decodes a curveType or parametricCurveType tag
+func iccCurve(tag []byte) (toneCurve, bool) {
+	if len(tag) < 12 {
+		return toneCurve{}, false
+	}
+	switch string(tag[:4]) {
+	case "curv":
+		n := int(binary.BigEndian.Uint32(tag[8:]))
+		switch {
+		case n == 0:
+			return toneCurve{params: []float64{1}}, true
+		case n == 1 && len(tag) >= 14:
+			return toneCurve{params: []float64{float64(binary.BigEndian.Uint16(tag[12:])) / 256}}, true
+		case len(tag) >= 12+2*n:
+			table := make([]uint16, n)
+			for i := range table {
+				table[i] = binary.BigEndian.Uint16(tag[12+2*i:])
+			}
+			return toneCurve{table: table}, true
+		}
+	case "para":
+		kind := int(binary.BigEndian.Uint16(tag[8:]))
+		counts := []int{1, 3, 4, 5, 7}
+		if kind >= len(counts) || len(tag) < 12+4*counts[kind] {
+			return toneCurve{}, false
+		}
+		params := make([]float64, counts[kind])
+		for i := range params {
+			params[i] = iccFixed(tag[12+4*i:])
+		}
+		return toneCurve{params: params, kind: kind}, true
+	}
+	return toneCurve{}, false
+}
+
+// iccFixed decodes an s15Fixed16Number
+func iccFixed(b []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+}
+
+// linear maps an encoded value in [0, 1] to linear light
+func (curve toneCurve) linear(v float64) float64 {
+	if curve.table != nil {
+		pos := v * float64(len(curve.table)-1)
+		i := min(int(pos), len(curve.table)-1)
+		next := min(i+1, len(curve.table)-1)
+		frac := pos - float64(i)
+		return (float64(curve.table[i])*(1-frac) + float64(curve.table[next])*frac) / 65535
+	}
+
+	p := curve.params
+	switch curve.kind {
+	case 1:
+		if v >= -p[2]/p[1] {
+			return math.Pow(p[1]*v+p[2], p[0])
+		}
+		return 0
+	case 2:
+		if v >= -p[2]/p[1] {
+			return math.Pow(p[1]*v+p[2], p[0]) + p[3]
+		}
+		return p[3]
+	case 3:
+		if v >= p[4] {
+			return math.Pow(p[1]*v+p[2], p[0])
+		}
+		return p[3] * v
+	case 4:
+		if v >= p[4] {
+			return math.Pow(p[1]*v+p[2], p[0]) + p[5]
+		}
+		return p[3]*v + p[6]
+	default:
+		return math.Pow(v, p[0])
+	}
+}
+
+// isSRGB reports whether the profile describes sRGB, so images need no conversion
+func (profile *iccProfile) isSRGB() bool {
+	return profile.ColorSpace == "RGB" && strings.Contains(strings.ToLower(profile.Description), "srgb")
+}
+
+// srgbEncode maps linear light in [0, 1] to an 8-bit sRGB value
+func srgbEncode(v float64) uint8 {
+	v = math.Max(0, math.Min(1, v))
+	if v <= 0.0031308 {
+		v *= 12.92
+	} else {
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+	return uint8(math.Round(v * 255))
+}
+
+// convertToSRGB converts an image from the colour space of its embedded ICC profile,
+// or from CMYK, to sRGB and returns the name of the applied transform, or "" if the
+// image was left unchanged. CMYK images use the device conversion of image/color, as
+// the lookup tables of CMYK profiles are not evaluated.
+func convertToSRGB(img image.Image, profile *iccProfile) (image.Image, string) {
+	bounds := img.Bounds()
+
+	if _, ok := img.(*image.CMYK); ok {
+		dst := image.NewRGBA(bounds)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				dst.Set(x, y, img.At(x, y))
+			}
+		}
+		return dst, "cmyk-to-srgb"
+	}
+
+	if profile == nil || !profile.hasMatrix || profile.isSRGB() {
+		return img, ""
+	}
+
+	// 8-bit lookup tables from encoded values to linear light for each channel
+	channels := 3
+	if profile.ColorSpace == "GRAY" {
+		channels = 1
+	}
+	var linear [3][256]float64
+	for c := 0; c < channels; c++ {
+		for v := range linear[c] {
+			linear[c][v] = profile.curves[c].linear(float64(v) / 255)
+		}
+	}
+
+	transform := "icc-to-srgb"
+	if profile.Description != "" {
+		transform = "icc-to-srgb: " + profile.Description
+	}
+
+	if profile.ColorSpace == "GRAY" {
+		dst := image.NewGray(bounds)
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			for x := bounds.Min.X; x < bounds.Max.X; x++ {
+				g := color.GrayModel.Convert(img.At(x, y)).(color.Gray)
+				dst.SetGray(x, y, color.Gray{Y: srgbEncode(linear[0][g.Y])})
+			}
+		}
+		return dst, transform
+	}
+
+	// Combine the profile's colorants with the conversion from PCS XYZ to linear sRGB
+	var m [3][3]float64
+	for r := 0; r < 3; r++ {
+		for c := 0; c < 3; c++ {
+			for k := 0; k < 3; k++ {
+				m[r][c] += xyzD50ToLinearSRGB[r][k] * profile.matrix[k][c]
+			}
+		}
+	}
+
+	dst := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			lr, lg, lb := linear[0][c.R], linear[1][c.G], linear[2][c.B]
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: srgbEncode(m[0][0]*lr + m[0][1]*lg + m[0][2]*lb),
+				G: srgbEncode(m[1][0]*lr + m[1][1]*lg + m[1][2]*lb),
+				B: srgbEncode(m[2][0]*lr + m[2][1]*lg + m[2][2]*lb),
+				A: c.A,
+			})
+		}
+	}
+	return dst, transform
+}
@@ -0,0 +1,147 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+// testICCProfile builds an ICC v2 RGB profile with sRGB primaries and linear tone curves
+func testICCProfile(description string) []byte {
+	type tag struct {
+		sig  string
+		data []byte
+	}
+
+	xyz := func(x, y, z float64) []byte {
+		b := []byte("XYZ \x00\x00\x00\x00")
+		for _, v := range []float64{x, y, z} {
+			b = binary.BigEndian.AppendUint32(b, uint32(int32(v*65536)))
+		}
+		return b
+	}
+	linear := []byte("curv\x00\x00\x00\x00\x00\x00\x00\x00")
+	desc := append([]byte("desc\x00\x00\x00\x00"), binary.BigEndian.AppendUint32(nil, uint32(len(description)+1))...)
+	desc = append(desc, description+"\x00"...)
+
+	tags := []tag{
+		{"desc", desc},
+		{"rXYZ", xyz(0.4360747, 0.2225045, 0.0139322)},
+		{"gXYZ", xyz(0.3850649, 0.7168786, 0.0971045)},
+		{"bXYZ", xyz(0.1430804, 0.0606169, 0.7141733)},
+		{"rTRC", linear},
+		{"gTRC", linear},
+		{"bTRC", linear},
+	}
+
+	header := make([]byte, iccHeaderSize)
+	copy(header[12:], "mntr")
+	copy(header[16:], "RGB XYZ ")
+	copy(header[36:], "acsp")
+
+	var table, data bytes.Buffer
+	table.Write(binary.BigEndian.AppendUint32(nil, uint32(len(tags))))
+	dataOffset := iccHeaderSize + 4 + 12*len(tags)
+	for _, t := range tags {
+		table.WriteString(t.sig)
+		table.Write(binary.BigEndian.AppendUint32(nil, uint32(dataOffset+data.Len())))
+		table.Write(binary.BigEndian.AppendUint32(nil, uint32(len(t.data))))
+		data.Write(t.data)
+		for data.Len()%4 != 0 {
+			data.WriteByte(0)
+		}
+	}
+
+	profile := append(header, table.Bytes()...)
+	profile = append(profile, data.Bytes()...)
+	binary.BigEndian.PutUint32(profile, uint32(len(profile)))
+	return profile
+}
+
+func TestConvertToSRGB(t *testing.T) {
+	profile, err := parseICCProfile(testICCProfile("Linear RGB"))
+	if err != nil {
+		t.Fatalf("parseICCProfile failed: %v", err)
+	}
+	if profile.ColorSpace != "RGB" || profile.Description != "Linear RGB" || !profile.hasMatrix {
+		t.Fatalf("Unexpected profile %+v", profile)
+	}
+
+	src := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+
+	// Linear mid gray is encoded brighter in sRGB, and stays neutral
+	converted, transform := convertToSRGB(src, profile)
+	if transform != "icc-to-srgb: Linear RGB" {
+		t.Errorf("Expected icc-to-srgb transform, got %q", transform)
+	}
+	c := color.NRGBAModel.Convert(converted.At(0, 0)).(color.NRGBA)
+	for name, v := range map[string]uint8{"red": c.R, "green": c.G, "blue": c.B} {
+		if v < 187 || v > 189 {
+			t.Errorf("Expected %s of 188, got %d", name, v)
+		}
+	}
+
+	// sRGB profiles need no conversion
+	srgb, err := parseICCProfile(testICCProfile("sRGB IEC61966-2.1"))
+	if err != nil {
+		t.Fatalf("parseICCProfile failed: %v", err)
+	}
+	if img, transform := convertToSRGB(src, srgb); transform != "" || img != image.Image(src) {
+		t.Errorf("Expected sRGB image to be unchanged, got transform %q", transform)
+	}
+
+	if _, err := parseICCProfile([]byte("not a profile")); err == nil {
+		t.Error("Expected error for invalid profile")
+	}
+}
+
+func TestConvertCMYKTiff(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "cmyk.tif")
+
+	// Pure cyan on the left, white on the right
+	writeRawTestTiff(t, tiffPath, []testTiffPage{{
+		width: 2, height: 2, pixels: []byte{
+			0xff, 0, 0, 0, 0, 0, 0, 0,
+			0xff, 0, 0, 0, 0, 0, 0, 0,
+		},
+		tags: map[uint16]testTiffTag{262: {tiffShort, []uint16{5}}},
+	}})
+
+	pages, err := OpenTiffPages(tiffPath)
+	if err != nil {
+		t.Fatalf("OpenTiffPages failed: %v", err)
+	}
+	defer pages.Close()
+
+	page, err := pages.Next()
+	if err != nil {
+		t.Fatalf("Next failed: %v", err)
+	}
+	img, err := page.Decode()
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	converted, transforms := applyTiffTransforms(page, img)
+	if len(transforms) != 1 || transforms[0] != "cmyk-to-srgb" {
+		t.Errorf("Expected cmyk-to-srgb transform, got %v", transforms)
+	}
+	if c := color.RGBAModel.Convert(converted.At(0, 1)).(color.RGBA); c != (color.RGBA{R: 0, G: 255, B: 255, A: 255}) {
+		t.Errorf("Expected cyan, got %v", c)
+	}
+	if c := color.RGBAModel.Convert(converted.At(1, 0)).(color.RGBA); c != (color.RGBA{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("Expected white, got %v", c)
+	}
+
+	details, err := ConvertTiffToPngWithOptions(tiffPath, dir, "cmyk-", nil)
+	if err != nil {
+		t.Fatalf("ConvertTiffToPngWithOptions failed: %v", err)
+	}
+	if details[0].Tiff.Photometric != "CMYK" || len(details[0].Transforms) != 1 {
+		t.Errorf("Expected CMYK page converted to sRGB, got %q with transforms %v", details[0].Tiff.Photometric, details[0].Transforms)
+	}
+}
@@ -0,0 +1,113 @@
+package tifpdf2png
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// manifestName is the name of the file written with Options.WriteManifest
+const manifestName = "manifest.json"
+
+// modulePath is the path of the library's module, used to find its version in the build info
+const modulePath = "github.com/dhushon/go-tifpdf2png"
+
+// Manifest describes a conversion and its output files. It is written as manifest.json
+// into destpath, or to Options.Sink, when Options.WriteManifest is set.
+type Manifest struct {
+	Source         string         `json:"source,omitempty"` // Path of the converted file, empty for documents read from a reader
+	SHA256         string         `json:"sha256"`           // Hex SHA-256 digest of the document
+	Format         string         `json:"format"`           // Format the document was converted as (e.g., "pdf", "tiff", "jpeg")
+	PageCount      int            `json:"page_count"`       // Total number of pages of the document
+	Options        *Options       `json:"options"`          // Options of the conversion, without passwords and callbacks
+	LibraryVersion string         `json:"library_version"`  // Version of this module, "(devel)" when built from a checkout
+	StartedAt      time.Time      `json:"started_at"`       // Time the conversion started
+	FinishedAt     time.Time      `json:"finished_at"`      // Time the conversion finished
+	Pages          []*ImageDetail `json:"pages"`            // Details of the converted pages
+}
+
+// writeManifest writes the manifest of a finished conversion to the sink of destpath
+func writeManifest(src *source, destpath string, opts *Options, pageCount int, started time.Time, imageDetails []*ImageDetail) error {
+	digest, err := sourceDigest(src)
+	if err != nil {
+		return err
+	}
+
+	manifest := &Manifest{
+		Source:         src.path,
+		SHA256:         digest,
+		Format:         formatName(src.ext),
+		PageCount:      pageCount,
+		Options:        opts,
+		LibraryVersion: libraryVersion(),
+		StartedAt:      started.UTC(),
+		FinishedAt:     time.Now().UTC(),
+		Pages:          imageDetails,
+	}
+	if manifest.Pages == nil {
+		manifest.Pages = []*ImageDetail{}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	url, err := writeSinkFile(opts.sinkFor(destpath), manifestName, append(data, '\n'))
+	if err != nil {
+		return err
+	}
+	slog.Info("Wrote manifest", "url", url)
+	return nil
+}
+
+// sourceDigest returns the hex SHA-256 digest of a source's contents
+func sourceDigest(src *source) (string, error) {
+	r, err := src.open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// formatName returns the name of the format selected by an extension
+func formatName(ext string) string {
+	switch {
+	case ext == ".tif" || ext == ".tiff":
+		return "tiff"
+	case rasterImageFormats[ext] != "":
+		return rasterImageFormats[ext]
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// libraryVersion returns the version of this module in the running binary's build info
+func libraryVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		if dep.Replace != nil {
+			return dep.Replace.Version
+		}
+		return dep.Version
+	}
+	return "unknown"
+}
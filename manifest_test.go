@@ -0,0 +1,91 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readTestManifest reads and decodes the manifest.json in dir
+func readTestManifest(t *testing.T, dir string) (*Manifest, []byte) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("Failed to decode manifest: %v", err)
+	}
+	return &manifest, data
+}
+
+func TestWriteManifest(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "scan.tif")
+	writeRawTestTiff(t, tiffPath, []testTiffPage{
+		{width: 2, height: 2, pixels: []byte{0, 255, 255, 255}},
+		{width: 3, height: 1, pixels: []byte{0, 0, 255}},
+	})
+	tiffData, err := os.ReadFile(tiffPath)
+	if err != nil {
+		t.Fatalf("Failed to read test TIFF: %v", err)
+	}
+	digest := sha256.Sum256(tiffData)
+
+	outDir := filepath.Join(dir, "tiff")
+	if err := os.Mkdir(outDir, 0755); err != nil {
+		t.Fatalf("Failed to create output directory: %v", err)
+	}
+	opts := &Options{Pages: "2", Password: "secret", WriteManifest: true}
+	details, err := ConvertTiffToPngWithOptions(tiffPath, outDir, "scan-", opts)
+	if err != nil {
+		t.Fatalf("ConvertTiffToPngWithOptions failed: %v", err)
+	}
+
+	manifest, data := readTestManifest(t, outDir)
+	if manifest.Source != tiffPath || manifest.SHA256 != hex.EncodeToString(digest[:]) || manifest.Format != "tiff" || manifest.PageCount != 2 {
+		t.Errorf("Unexpected manifest source %q, digest %q, format %q and page count %d", manifest.Source, manifest.SHA256, manifest.Format, manifest.PageCount)
+	}
+	if len(manifest.Pages) != 1 || manifest.Pages[0].URL != details[0].URL || manifest.Options.Pages != "2" {
+		t.Errorf("Expected page 2 with its options, got %d pages and %+v", len(manifest.Pages), manifest.Options)
+	}
+	if manifest.LibraryVersion == "" || manifest.StartedAt.IsZero() || manifest.FinishedAt.Before(manifest.StartedAt) {
+		t.Errorf("Unexpected version %q and times %v - %v", manifest.LibraryVersion, manifest.StartedAt, manifest.FinishedAt)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Error("Manifest contains the password")
+	}
+
+	// Documents read from a reader have no source path and their detected format
+	pdfPath := filepath.Join(dir, "doc.pdf")
+	writeTestPdf(t, pdfPath, []string{"One"})
+	pdfData, err := os.ReadFile(pdfPath)
+	if err != nil {
+		t.Fatalf("Failed to read test PDF: %v", err)
+	}
+	outDir = filepath.Join(dir, "pdf")
+	if err := os.Mkdir(outDir, 0755); err != nil {
+		t.Fatalf("Failed to create output directory: %v", err)
+	}
+	if _, err := ConvertReaderWithOptions(bytes.NewReader(pdfData), outDir, "doc-", &Options{DPI: 72, WriteManifest: true}); err != nil {
+		t.Fatalf("ConvertReaderWithOptions failed: %v", err)
+	}
+	manifest, _ = readTestManifest(t, outDir)
+	if manifest.Source != "" || manifest.Format != "pdf" || manifest.PageCount != 1 || len(manifest.Pages) != 1 {
+		t.Errorf("Unexpected manifest %+v", manifest)
+	}
+
+	// No manifest is written unless requested
+	if _, err := ConvertTiffToPngWithOptions(tiffPath, dir, "plain-", nil); err != nil {
+		t.Fatalf("ConvertTiffToPngWithOptions failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, manifestName)); !os.IsNotExist(err) {
+		t.Errorf("Expected no manifest, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package tifpdf2png
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// MontageOptions controls the layout of a contact sheet produced by RenderMontage
+type MontageOptions struct {
+	Columns        int  // Number of grid columns (0 picks a roughly square grid)
+	ThumbnailWidth int  // Width of each page cell in pixels
+	Padding        int  // Spacing between cells and around the sheet in pixels
+	PageNumbers    bool // Draw the page number under each thumbnail
+	MarkBlankPages bool // Overlay a marker on pages with no visible content
+}
+
+// DefaultMontageOptions returns the options used when RenderMontage is called with nil
+func DefaultMontageOptions() *MontageOptions {
+	return &MontageOptions{
+		ThumbnailWidth: 300,
+		Padding:        16,
+		PageNumbers:    true,
+		MarkBlankPages: true,
+	}
+}
+
+// blankInkRatio is the fraction of dark pixels below which a page is considered blank
+const blankInkRatio = 0.001
+
+const montageLabelHeight = 20
+
+// RenderMontage lays out the processed pages described by details into a single
+// PNG grid written to outputFilepath and returns an ImageDetail for the sheet
+func RenderMontage(details []*ImageDetail, outputFilepath string, opts *MontageOptions) (*ImageDetail, error) {
+	if len(details) == 0 {
+		return nil, fmt.Errorf("no pages to render in montage")
+	}
+
+	defaults := DefaultMontageOptions()
+	if opts == nil {
+		opts = defaults
+	}
+	thumbWidth := opts.ThumbnailWidth
+	if thumbWidth <= 0 {
+		thumbWidth = defaults.ThumbnailWidth
+	}
+	padding := opts.Padding
+	if padding < 0 {
+		padding = 0
+	}
+	columns := opts.Columns
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(len(details)))))
+	}
+	rows := (len(details) + columns - 1) / columns
+
+	thumbnails := make([]image.Image, len(details))
+	blank := make([]bool, len(details))
+	cellHeight := 0
+	for i, detail := range details {
+		img, err := loadPng(detail.URL)
+		if err != nil {
+			slog.Error("RenderMontage: load page", "page", detail.Page, "error", err)
+			return nil, err
+		}
+
+		blank[i] = opts.MarkBlankPages && isBlankImage(img)
+		thumbnails[i] = imaging.Fit(img, thumbWidth, thumbWidth*2, imaging.Lanczos)
+		if h := thumbnails[i].Bounds().Dy(); h > cellHeight {
+			cellHeight = h
+		}
+	}
+
+	labelHeight := 0
+	if opts.PageNumbers {
+		labelHeight = montageLabelHeight
+	}
+
+	sheetWidth := columns*(thumbWidth+padding) + padding
+	sheetHeight := rows*(cellHeight+labelHeight+padding) + padding
+	sheet := image.NewRGBA(image.Rect(0, 0, sheetWidth, sheetHeight))
+	draw.Draw(sheet, sheet.Bounds(), &image.Uniform{color.RGBA{224, 224, 224, 255}}, image.Point{}, draw.Src)
+
+	for i, thumb := range thumbnails {
+		col, row := i%columns, i/columns
+		cellX := padding + col*(thumbWidth+padding)
+		cellY := padding + row*(cellHeight+labelHeight+padding)
+
+		tb := thumb.Bounds()
+		origin := image.Point{
+			X: cellX + (thumbWidth-tb.Dx())/2,
+			Y: cellY + (cellHeight-tb.Dy())/2,
+		}
+		target := image.Rectangle{Min: origin, Max: origin.Add(tb.Size())}
+		draw.Draw(sheet, target, thumb, tb.Min, draw.Src)
+
+		if blank[i] {
+			markBlankCell(sheet, target)
+		}
+
+		if opts.PageNumbers {
+			label := strconv.Itoa(details[i].Page)
+			if blank[i] {
+				label += " (blank)"
+			}
+			drawLabel(sheet, label, cellX, cellY+cellHeight, thumbWidth, labelHeight)
+		}
+	}
+
+	sink := DirSink(filepath.Dir(outputFilepath))
+	if _, err := saveImageAsPng(sink, sheet, filepath.Base(outputFilepath)); err != nil {
+		return nil, err
+	}
+
+	slog.Debug("Saved montage",
+		"filename", outputFilepath,
+		"pages", len(details),
+		"grid", fmt.Sprintf("%dx%d", columns, rows))
+
+	return &ImageDetail{
+		ActualType: "png",
+		Page:       1,
+		Pages:      1,
+		URL:        outputFilepath,
+		Width:      sheetWidth,
+		Height:     sheetHeight,
+		Format:     "png",
+		Quality:    95.0,
+	}, nil
+}
+
+// isBlankImage reports whether an image contains (almost) no dark pixels
+func isBlankImage(img image.Image) bool {
+	bounds := img.Bounds()
+	total := bounds.Dx() * bounds.Dy()
+	if total == 0 {
+		return true
+	}
+
+	dark := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			if uint8(a>>8) == 0 {
+				continue
+			}
+			r8, g8, b8 := uint8(r>>8), uint8(g>>8), uint8(b>>8)
+			luminance := 0.299*float64(r8) + 0.587*float64(g8) + 0.114*float64(b8)
+			if luminance < 128 {
+				dark++
+			}
+		}
+	}
+
+	return float64(dark)/float64(total) < blankInkRatio
+}
+
+// markBlankCell draws a red cross over the given rectangle
+func markBlankCell(dst *image.RGBA, r image.Rectangle) {
+	red := color.RGBA{220, 0, 0, 255}
+	w, h := r.Dx(), r.Dy()
+	if w == 0 || h == 0 {
+		return
+	}
+	steps := w
+	if h > steps {
+		steps = h
+	}
+	for i := 0; i < steps; i++ {
+		x := r.Min.X + i*w/steps
+		y := r.Min.Y + i*h/steps
+		for d := -1; d <= 1; d++ {
+			dst.Set(x+d, y, red)
+			dst.Set(r.Max.X-1-(x-r.Min.X)+d, y, red)
+		}
+	}
+}
+
+// drawLabel writes text centred horizontally in the given label area
+func drawLabel(dst *image.RGBA, text string, x, y, width, height int) {
+	face := basicfont.Face7x13
+	drawer := &font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(color.Black),
+		Face: face,
+	}
+	textWidth := drawer.MeasureString(text).Ceil()
+	baseline := y + (height+face.Ascent-face.Descent)/2
+	drawer.Dot = fixed.P(x+(width-textWidth)/2, baseline)
+	drawer.DrawString(text)
+}
+
+// loadPng reads a PNG file from disk
+func loadPng(filename string) (image.Image, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			slog.Warn("Failed to close PNG file", "error", err)
+		}
+	}()
+
+	return png.Decode(file)
+}
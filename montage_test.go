@@ -0,0 +1,92 @@
+package tifpdf2png
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// writeTestPage writes a white PNG page, optionally with a black block of content
+func writeTestPage(t *testing.T, dir string, page int, width, height int, content bool) *ImageDetail {
+	t.Helper()
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{color.White}, image.Point{}, draw.Src)
+	if content {
+		draw.Draw(img, image.Rect(width/4, height/4, width*3/4, height*3/4), &image.Uniform{color.Black}, image.Point{}, draw.Src)
+	}
+
+	path, err := saveImageAsPng(DirSink(dir), img, "page-"+strconv.Itoa(page)+".png")
+	if err != nil {
+		t.Fatalf("Failed to write test page: %v", err)
+	}
+
+	return &ImageDetail{ActualType: "png", Page: page, URL: path, Width: width, Height: height, Format: "png"}
+}
+
+func TestRenderMontage(t *testing.T) {
+	dir := t.TempDir()
+
+	details := []*ImageDetail{
+		writeTestPage(t, dir, 1, 200, 300, true),
+		writeTestPage(t, dir, 2, 200, 300, false),
+		writeTestPage(t, dir, 3, 300, 200, true),
+	}
+
+	opts := &MontageOptions{
+		Columns:        2,
+		ThumbnailWidth: 100,
+		Padding:        10,
+		PageNumbers:    true,
+		MarkBlankPages: true,
+	}
+
+	montagePath := filepath.Join(dir, "montage.png")
+	detail, err := RenderMontage(details, montagePath, opts)
+	if err != nil {
+		t.Fatalf("RenderMontage failed: %v", err)
+	}
+
+	if _, err := os.Stat(montagePath); err != nil {
+		t.Fatalf("Montage file does not exist: %s", montagePath)
+	}
+
+	// 2 columns of 100px plus 3 paddings; 2 rows of 150px cells plus labels
+	if detail.Width != 2*100+3*10 {
+		t.Errorf("Expected montage width %d, got %d", 2*100+3*10, detail.Width)
+	}
+	if detail.Height != 2*(150+montageLabelHeight+10)+10 {
+		t.Errorf("Expected montage height %d, got %d", 2*(150+montageLabelHeight+10)+10, detail.Height)
+	}
+}
+
+func TestIsBlankImage(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, tc := range []struct {
+		content bool
+		blank   bool
+	}{
+		{content: false, blank: true},
+		{content: true, blank: false},
+	} {
+		detail := writeTestPage(t, dir, 1, 50, 50, tc.content)
+		img, err := loadPng(detail.URL)
+		if err != nil {
+			t.Fatalf("Failed to load test page: %v", err)
+		}
+		if got := isBlankImage(img); got != tc.blank {
+			t.Errorf("isBlankImage(content=%v) = %v, want %v", tc.content, got, tc.blank)
+		}
+	}
+}
+
+func TestRenderMontageNoPages(t *testing.T) {
+	if _, err := RenderMontage(nil, filepath.Join(t.TempDir(), "montage.png"), nil); err == nil {
+		t.Error("Expected error for empty page list, got nil")
+	}
+}
@@ -0,0 +1,199 @@
+package tifpdf2png
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OutputFormat selects the file format pages are written in
+type OutputFormat string
+
+// Supported output formats. SVG and HTML keep PDF pages as vectors and text.
+const (
+	FormatPNG  OutputFormat = "png"
+	FormatSVG  OutputFormat = "svg"
+	FormatHTML OutputFormat = "html"
+)
+
+// SubImageMode selects which images of a TIFF page are converted. A page's image file
+// directory can carry further images in SubIFDs, typically reduced-resolution previews.
+type SubImageMode string
+
+// Supported sub-image modes
+const (
+	SubImagesFirst   SubImageMode = "first"   // Only the page's main image (default)
+	SubImagesAll     SubImageMode = "all"     // Every image, sub-images named <prefix><page>-<index>.png
+	SubImagesLargest SubImageMode = "largest" // The image with the highest resolution
+)
+
+// PageSelection selects pages by 1-based number as a comma-separated list of pages and
+// ranges, such as "1-3,5,8-". An open range runs to the last page; empty selects all pages.
+type PageSelection string
+
+// PageHandler is called with the ImageDetail of each page as soon as the page is saved.
+// Returning an error stops the conversion, which then fails with that error.
+type PageHandler func(detail *ImageDetail) error
+
+// Options controls optional conversion behaviour. A nil *Options is
+// equivalent to the zero value, which matches the behaviour of the
+// ConvertXxxToPngWithImageDetails functions.
+type Options struct {
+	Format OutputFormat `json:"format,omitempty"` // Output format for PDF pages; empty means FormatPNG
+
+	DPI   float64       `json:"dpi,omitempty"`   // Resolution PDF and other document pages are rendered at; 0 means 300
+	Pages PageSelection `json:"pages,omitempty"` // Pages to convert; empty converts every page
+
+	NoCrop     bool  `json:"no_crop,omitempty"`     // Keep whole pages instead of cropping them to their content
+	NoBinarize bool  `json:"no_binarize,omitempty"` // Keep the colours of pages instead of converting them to black on white
+	Threshold  uint8 `json:"threshold,omitempty"`   // Luminance below which pixels become black when binarizing; 0 means 128
+
+	ExtractText bool `json:"extract_text,omitempty"` // Extract the PDF text layer and word boxes into ImageDetail.Text
+	IncludeHTML bool `json:"include_html,omitempty"` // Also include MuPDF's positioned HTML in ImageDetail.Text (requires ExtractText)
+
+	ExtractLinks         bool `json:"extract_links,omitempty"`           // List the URIs of page links in ImageDetail.Links
+	ExtractFullPageImage bool `json:"extract_full_page_image,omitempty"` // Save pages that are a single full-page raster at the image's native resolution instead of rendering
+
+	ExtractAnnotations bool `json:"extract_annotations,omitempty"` // List annotations and form fields in ImageDetail.Annotations and DocumentInfo.FormFields
+	RenderAnnotations  bool `json:"render_annotations,omitempty"`  // Draw markup annotations (comments, highlights, stamps, ...) onto page images
+	RenderFormFields   bool `json:"render_form_fields,omitempty"`  // Draw form field widgets, including their filled values, onto page images
+	RenderSignatures   bool `json:"render_signatures,omitempty"`   // Draw signature field appearances onto page images
+
+	TargetDPI    float64 `json:"target_dpi,omitempty"`    // Resample TIFF pages to square pixels at this resolution; 0 keeps the source pixels
+	SquarePixels bool    `json:"square_pixels,omitempty"` // Resample TIFF pages with non-square pixels (e.g. 204x98 DPI fax) to the higher of the two resolutions
+
+	SubImages SubImageMode `json:"sub_images,omitempty"` // TIFF sub-images to convert; empty means SubImagesFirst
+
+	WriteWorldFile bool `json:"write_world_file,omitempty"` // Write a world file (.pgw) next to each PNG converted from a GeoTIFF
+	WriteManifest  bool `json:"write_manifest,omitempty"`   // Write a manifest.json describing the conversion next to the pages
+
+	Password         string           `json:"-"` // Password for encrypted PDFs (user or owner password)
+	PasswordProvider PasswordProvider `json:"-"` // Called for encrypted PDFs when Password is empty
+
+	OnPage PageHandler `json:"-"` // Called with each page's ImageDetail as soon as the page is saved
+
+	Sink PageSink `json:"-"` // Receives the output files; nil writes them into destpath
+}
+
+// rendersAnnotations reports whether any annotation appearances are drawn onto page images
+func (opts *Options) rendersAnnotations() bool {
+	return opts.RenderAnnotations || opts.RenderFormFields || opts.RenderSignatures
+}
+
+// pageSaved passes a saved page's ImageDetail to the OnPage handler, if any
+func (opts *Options) pageSaved(detail *ImageDetail) error {
+	if opts.OnPage == nil {
+		return nil
+	}
+	return opts.OnPage(detail)
+}
+
+// sinkFor returns the sink output files are written to
+func (opts *Options) sinkFor(destpath string) PageSink {
+	if opts.Sink != nil {
+		return opts.Sink
+	}
+	return DirSink(destpath)
+}
+
+// orDefault returns opts, or zero-value options when opts is nil
+func (opts *Options) orDefault() *Options {
+	if opts == nil {
+		return &Options{}
+	}
+	return opts
+}
+
+// Validate checks that the options are supported
+func (opts *Options) Validate() error {
+	if err := opts.Format.validate(); err != nil {
+		return err
+	}
+	if err := opts.SubImages.validate(); err != nil {
+		return err
+	}
+	if opts.DPI < 0 {
+		return fmt.Errorf("invalid render resolution %g DPI", opts.DPI)
+	}
+	_, err := opts.Pages.ranges()
+	return err
+}
+
+// renderDPI returns the resolution pages are rendered at
+func (opts *Options) renderDPI() float64 {
+	if opts.DPI > 0 {
+		return opts.DPI
+	}
+	return pdfRenderDPI
+}
+
+// threshold returns the binarization threshold
+func (opts *Options) threshold() uint8 {
+	if opts.Threshold > 0 {
+		return opts.Threshold
+	}
+	return defaultBinarizeThreshold
+}
+
+// validate checks that the output format is supported
+func (f OutputFormat) validate() error {
+	switch f {
+	case "", FormatPNG, FormatSVG, FormatHTML:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q", string(f))
+	}
+}
+
+// validate checks that the sub-image mode is supported
+func (m SubImageMode) validate() error {
+	switch m {
+	case "", SubImagesFirst, SubImagesAll, SubImagesLargest:
+		return nil
+	default:
+		return fmt.Errorf("unsupported sub-image mode %q", string(m))
+	}
+}
+
+// ranges parses the selection into inclusive page ranges, where a last page of 0 means
+// the end of the document
+func (s PageSelection) ranges() ([][2]int, error) {
+	var ranges [][2]int
+	if strings.TrimSpace(string(s)) == "" {
+		return nil, nil
+	}
+	for _, part := range strings.Split(string(s), ",") {
+		first, last, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		from, err := strconv.Atoi(strings.TrimSpace(first))
+		if err != nil || from < 1 {
+			return nil, fmt.Errorf("invalid page selection %q", string(s))
+		}
+		to := from
+		if isRange {
+			to = 0
+			if last = strings.TrimSpace(last); last != "" {
+				to, err = strconv.Atoi(last)
+				if err != nil || to < from {
+					return nil, fmt.Errorf("invalid page selection %q", string(s))
+				}
+			}
+		}
+		ranges = append(ranges, [2]int{from, to})
+	}
+	return ranges, nil
+}
+
+// includes reports whether the 1-based page is selected. Invalid selections, which are
+// rejected by validate, select every page.
+func (s PageSelection) includes(page int) bool {
+	ranges, err := s.ranges()
+	if err != nil || ranges == nil {
+		return true
+	}
+	for _, r := range ranges {
+		if page >= r[0] && (r[1] == 0 || page <= r[1]) {
+			return true
+		}
+	}
+	return false
+}
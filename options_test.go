@@ -0,0 +1,110 @@
+package tifpdf2png
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPageSelection(t *testing.T) {
+	selection := PageSelection("1-3, 5,8-")
+	for page, want := range map[int]bool{1: true, 3: true, 4: false, 5: true, 7: false, 8: true, 100: true} {
+		if got := selection.includes(page); got != want {
+			t.Errorf("includes(%d) = %v, want %v", page, got, want)
+		}
+	}
+	if !PageSelection("").includes(42) {
+		t.Error("Expected an empty selection to include every page")
+	}
+
+	for _, invalid := range []string{"0", "a", "3-1", "1,,2", "-2"} {
+		if err := (&Options{Pages: PageSelection(invalid)}).Validate(); err == nil {
+			t.Errorf("Expected page selection %q to be rejected", invalid)
+		}
+	}
+	if err := (&Options{DPI: -1}).Validate(); err == nil {
+		t.Error("Expected a negative DPI to be rejected")
+	}
+}
+
+func TestConvertPdfPageOptions(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "pages.pdf")
+	writeTestPdf(t, pdfPath, []string{"First page", "Second page", "Third page"})
+
+	opts := &Options{Pages: "2-", DPI: 72, NoCrop: true, NoBinarize: true}
+	details, err := ConvertPdfToPngWithOptions(pdfPath, dir, "pages-", opts)
+	if err != nil {
+		t.Fatalf("ConvertPdfToPngWithOptions failed: %v", err)
+	}
+	if len(details) != 2 || details[0].Page != 2 || details[1].Page != 3 {
+		t.Fatalf("Expected pages 2 and 3, got %d details", len(details))
+	}
+
+	// Uncropped Letter pages at 72 DPI are 612x792, anti-aliased text keeps its grays
+	detail := details[0]
+	if detail.Width != 612 || detail.Height != 792 || detail.CropDetail != nil {
+		t.Errorf("Expected an uncropped 612x792 page, got %dx%d crop %+v", detail.Width, detail.Height, detail.CropDetail)
+	}
+	if detail.DPI == nil || detail.DPI.X != 72 {
+		t.Errorf("Expected 72 DPI, got %+v", detail.DPI)
+	}
+	img := readTestPng(t, detail.URL)
+	gray := false
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y && !gray; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if r, _, _, _ := img.At(x, y).RGBA(); r != 0 && r != 0xffff {
+				gray = true
+				break
+			}
+		}
+	}
+	if !gray {
+		t.Error("Expected gray anti-aliasing to be kept without binarization")
+	}
+}
+
+func TestConvertTiffThreshold(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "gray.tif")
+	pixels := make([]byte, 4*4)
+	for i := range pixels {
+		pixels[i] = 0xff
+	}
+	pixels[5] = 100
+	writeRawTestTiff(t, tiffPath, []testTiffPage{{width: 4, height: 4, pixels: pixels}})
+
+	for _, tc := range []struct {
+		threshold uint8
+		want      uint32
+	}{
+		{0, 0},       // Default threshold of 128 makes the pixel black
+		{90, 0xffff}, // Lower threshold turns it into background
+	} {
+		details, err := ConvertTiffToPngWithOptions(tiffPath, dir, "gray-", &Options{Threshold: tc.threshold})
+		if err != nil {
+			t.Fatalf("ConvertTiffToPngWithOptions failed: %v", err)
+		}
+		if r, _, _, _ := readTestPng(t, details[0].URL).At(1, 1).RGBA(); r != tc.want {
+			t.Errorf("Threshold %d: expected pixel value %#x, got %#x", tc.threshold, tc.want, r)
+		}
+	}
+}
+
+// readTestPng decodes a PNG written by a conversion
+func readTestPng(t *testing.T, path string) image.Image {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open PNG: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode PNG: %v", err)
+	}
+	return img
+}
@@ -0,0 +1,48 @@
+package tifpdf2png
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// orientationTransforms names the transform that displays an image stored with each
+// TIFF/EXIF Orientation value (TIFF 6.0, section 8). Orientation 1 needs none.
+var orientationTransforms = map[int]string{
+	2: "flip-horizontal",
+	3: "rotate-180",
+	4: "flip-vertical",
+	5: "transpose",
+	6: "rotate-90-cw",
+	7: "transverse",
+	8: "rotate-90-ccw",
+}
+
+// applyOrientation transforms an image into its display orientation, returning the name
+// of the applied transform or "" if the image is already upright
+func applyOrientation(img image.Image, orientation int) (image.Image, string) {
+	switch orientation {
+	case 2:
+		img = imaging.FlipH(img)
+	case 3:
+		img = imaging.Rotate180(img)
+	case 4:
+		img = imaging.FlipV(img)
+	case 5:
+		img = imaging.Transpose(img)
+	case 6:
+		img = imaging.Rotate270(img)
+	case 7:
+		img = imaging.Transverse(img)
+	case 8:
+		img = imaging.Rotate90(img)
+	default:
+		return img, ""
+	}
+	return img, orientationTransforms[orientation]
+}
+
+// swapsAxes reports whether an orientation exchanges the width and height of an image
+func swapsAxes(orientation int) bool {
+	return orientation >= 5 && orientation <= 8
+}
@@ -0,0 +1,61 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertOrientedTiff(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "photo.tif")
+
+	// A 3x2 light page with a dark top-left pixel, stored rotated 90 degrees counter-clockwise
+	pixels := bytes.Repeat([]byte{0xe0}, 3*2)
+	pixels[0] = 0x10
+	writeRawTestTiff(t, tiffPath, []testTiffPage{{
+		width: 3, height: 2, pixels: pixels,
+		tags: map[uint16]testTiffTag{
+			274: {tiffShort, []uint16{6}},
+			282: {tiffRational, [][2]uint32{{200, 1}}},
+			283: {tiffRational, [][2]uint32{{100, 1}}},
+		},
+	}})
+
+	details, err := ConvertTiffToPngWithOptions(tiffPath, dir, "photo-", nil)
+	if err != nil {
+		t.Fatalf("ConvertTiffToPngWithOptions failed: %v", err)
+	}
+
+	detail := details[0]
+	if detail.Width != 2 || detail.Height != 3 {
+		t.Errorf("Expected upright 2x3 image, got %dx%d", detail.Width, detail.Height)
+	}
+	if detail.Tiff.Orientation != 6 || len(detail.Transforms) != 1 || detail.Transforms[0] != "rotate-90-cw" {
+		t.Errorf("Expected rotate-90-cw for orientation 6, got %v (orientation %d)", detail.Transforms, detail.Tiff.Orientation)
+	}
+	if detail.DPI == nil || *detail.DPI != (Resolution{X: 100, Y: 200}) {
+		t.Errorf("Expected output DPI to follow the rotated axes, got %+v", detail.DPI)
+	}
+
+	file, err := os.Open(detail.URL)
+	if err != nil {
+		t.Fatalf("Failed to open output: %v", err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatalf("Failed to decode output: %v", err)
+	}
+
+	// The stored top-left pixel is displayed at the top right
+	if c := color.GrayModel.Convert(img.At(1, 0)).(color.Gray); c.Y != 0 {
+		t.Errorf("Expected black pixel at top right, got %v", c)
+	}
+	if c := color.GrayModel.Convert(img.At(0, 0)).(color.Gray); c.Y != 255 {
+		t.Errorf("Expected white pixel at top left, got %v", c)
+	}
+}
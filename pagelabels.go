@@ -0,0 +1,172 @@
+package tifpdf2png
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const (
+	// maxNumberTreeDepth bounds the /Kids levels of a number tree followed, against cycles
+	maxNumberTreeDepth = 32
+
+	// maxPageLabelStart is the largest /St accepted; larger values are ignored
+	maxPageLabelStart = 1000000
+
+	// Roman and alphabetic labels grow with their number, so larger numbers are decimal
+	maxRomanNumeral    = 4999
+	maxAlphabeticLabel = 26 * 100
+)
+
+// pageLabelRange is one entry of a PDF /PageLabels number tree
+type pageLabelRange struct {
+	start  int    // First page index (0-based) the range applies to
+	style  string // Numbering style: D, R, r, A, a or empty for prefix only
+	prefix string // Label prefix
+	first  int    // Numeric value of the first page in the range
+}
+
+// pageLabels returns the label of every page as defined by the ranges of the document's
+// /PageLabels tree, falling back to decimal page numbers
+func pageLabels(ranges []pageLabelRange, pageCount int) []string {
+	labels := make([]string, pageCount)
+	for i := range labels {
+		labels[i] = strconv.Itoa(i + 1)
+	}
+
+	for idx, r := range ranges {
+		end := pageCount
+		if idx+1 < len(ranges) && ranges[idx+1].start < end {
+			end = ranges[idx+1].start
+		}
+		for page := r.start; page < end; page++ {
+			labels[page] = r.label(page - r.start)
+		}
+	}
+
+	return labels
+}
+
+// label formats the label of the page at the given index within the range
+func (r pageLabelRange) label(index int) string {
+	n := r.first + index
+	switch r.style {
+	case "D":
+		return r.prefix + strconv.Itoa(n)
+	case "R":
+		return r.prefix + romanNumeral(n)
+	case "r":
+		return r.prefix + strings.ToLower(romanNumeral(n))
+	case "A":
+		return r.prefix + alphabeticLabel(n)
+	case "a":
+		return r.prefix + strings.ToLower(alphabeticLabel(n))
+	default:
+		return r.prefix
+	}
+}
+
+// readPageLabelRanges reads the ranges of the catalog's /PageLabels number tree, sorted
+// by their first page
+func readPageLabelRanges(ctx *model.Context) []pageLabelRange {
+	catalog, err := ctx.Catalog()
+	if err != nil {
+		slog.Warn("readPageLabelRanges: catalog", "error", err)
+		return nil
+	}
+	root, err := ctx.DereferenceDict(catalog["PageLabels"])
+	if err != nil || root == nil {
+		return nil
+	}
+
+	var ranges []pageLabelRange
+	readNumberTree(ctx, root, 0, func(start int, value types.Object) {
+		d, err := ctx.DereferenceDict(value)
+		if err != nil || d == nil || start < 0 {
+			return
+		}
+		r := pageLabelRange{start: start, first: 1}
+		if style, err := ctx.Dereference(d["S"]); err == nil {
+			if name, ok := style.(types.Name); ok {
+				r.style = name.Value()
+			}
+		}
+		if prefix, err := ctx.DereferenceText(d["P"]); err == nil {
+			r.prefix = prefix
+		}
+		if first, err := ctx.DereferenceInteger(d["St"]); err == nil && first != nil {
+			if v := first.Value(); v >= 1 && v <= maxPageLabelStart {
+				r.first = v
+			} else {
+				slog.Warn("readPageLabelRanges: ignoring /St out of range", "start", v)
+			}
+		}
+		ranges = append(ranges, r)
+	})
+
+	slices.SortStableFunc(ranges, func(a, b pageLabelRange) int { return a.start - b.start })
+	return slices.CompactFunc(ranges, func(a, b pageLabelRange) bool { return a.start == b.start })
+}
+
+// readNumberTree calls fn with the key and value of every /Nums entry of a number tree
+// node and its /Kids
+func readNumberTree(ctx *model.Context, node types.Dict, depth int, fn func(key int, value types.Object)) {
+	if depth > maxNumberTreeDepth {
+		return
+	}
+	nums, err := ctx.DereferenceArray(node["Nums"])
+	if err != nil {
+		slog.Debug("readNumberTree: /Nums", "error", err)
+	}
+	for i := 0; i+1 < len(nums); i += 2 {
+		key, err := ctx.DereferenceInteger(nums[i])
+		if err != nil || key == nil {
+			continue
+		}
+		fn(key.Value(), nums[i+1])
+	}
+
+	kids, err := ctx.DereferenceArray(node["Kids"])
+	if err != nil {
+		slog.Debug("readNumberTree: /Kids", "error", err)
+	}
+	for _, kid := range kids {
+		if d, err := ctx.DereferenceDict(kid); err == nil && d != nil {
+			readNumberTree(ctx, d, depth+1, fn)
+		}
+	}
+}
+
+// romanNumeral formats n as an upper case roman numeral, or in decimal above
+// maxRomanNumeral
+func romanNumeral(n int) string {
+	if n <= 0 || n > maxRomanNumeral {
+		return strconv.Itoa(n)
+	}
+
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+
+	var b strings.Builder
+	for i, v := range values {
+		for n >= v {
+			b.WriteString(symbols[i])
+			n -= v
+		}
+	}
+	return b.String()
+}
+
+// alphabeticLabel formats n as A..Z, AA..ZZ, AAA.. as defined for PDF page labels, or in
+// decimal above maxAlphabeticLabel
+func alphabeticLabel(n int) string {
+	if n <= 0 || n > maxAlphabeticLabel {
+		return strconv.Itoa(n)
+	}
+	letter := string(rune('A' + (n-1)%26))
+	return strings.Repeat(letter, (n-1)/26+1)
+}
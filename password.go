@@ -0,0 +1,58 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+var (
+	// ErrEncrypted is returned when a document requires a password and none was supplied
+	ErrEncrypted = errors.New("document is encrypted and requires a password")
+
+	// ErrBadPassword is returned when the supplied password does not open the document
+	ErrBadPassword = errors.New("incorrect password for encrypted document")
+)
+
+// PasswordProvider returns the password of an encrypted document. It is only called
+// for documents that need a password, with the filename passed to the converter, or an
+// empty name for documents read from a reader.
+type PasswordProvider func(filename string) (string, error)
+
+// disablePdfcpuConfig keeps pdfcpu from creating a configuration directory on first use
+var disablePdfcpuConfig sync.Once
+
+// decryptPdf decrypts a password protected PDF into memory using either its user or owner password
+func decryptPdf(src *source, password string) ([]byte, error) {
+	disablePdfcpuConfig.Do(api.DisableConfigDir)
+
+	in, err := src.open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := in.Close(); err != nil {
+			slog.Warn("Failed to close PDF file", "error", err)
+		}
+	}()
+
+	conf := model.NewDefaultConfiguration()
+	conf.UserPW = password
+	conf.OwnerPW = password
+	conf.ValidationMode = model.ValidationRelaxed
+
+	var out bytes.Buffer
+	if err := api.Decrypt(in, &out, conf); err != nil {
+		if errors.Is(err, pdfcpu.ErrWrongPassword) {
+			return nil, ErrBadPassword
+		}
+		return nil, err
+	}
+
+	return out.Bytes(), nil
+}
@@ -0,0 +1,62 @@
+package tifpdf2png
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// writeEncryptedTestPdf writes a test PDF protected with the given user password
+func writeEncryptedTestPdf(t *testing.T, dir string, userPW string) string {
+	t.Helper()
+
+	plainPath := filepath.Join(dir, "plain.pdf")
+	writeTestPdf(t, plainPath, []string{"Secret"})
+
+	api.DisableConfigDir()
+	encryptedPath := filepath.Join(dir, "encrypted.pdf")
+	if err := api.EncryptFile(plainPath, encryptedPath, model.NewAESConfiguration(userPW, "owner-"+userPW, 256)); err != nil {
+		t.Fatalf("Failed to encrypt test PDF: %v", err)
+	}
+	return encryptedPath
+}
+
+func TestConvertEncryptedPdf(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := writeEncryptedTestPdf(t, dir, "s3cret")
+
+	if _, err := ConvertPdfToPngWithImageDetails(pdfPath, dir, "none-"); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("Expected ErrEncrypted without password, got %v", err)
+	}
+
+	if _, err := ConvertPdfToPngWithOptions(pdfPath, dir, "bad-", &Options{Password: "wrong"}); !errors.Is(err, ErrBadPassword) {
+		t.Errorf("Expected ErrBadPassword for wrong password, got %v", err)
+	}
+
+	details, err := ConvertPdfToPngWithOptions(pdfPath, dir, "good-", &Options{Password: "s3cret", ExtractText: true})
+	if err != nil {
+		t.Fatalf("Conversion with password failed: %v", err)
+	}
+	if len(details) != 1 || details[0].Text == nil || details[0].Text.Content == "" {
+		t.Errorf("Expected one page with text, got %+v", details)
+	}
+
+	var asked string
+	provider := func(filename string) (string, error) {
+		asked = filename
+		return "owner-s3cret", nil
+	}
+	info, err := ReadPdfDocumentInfo(pdfPath, &Options{PasswordProvider: provider})
+	if err != nil {
+		t.Fatalf("ReadPdfDocumentInfo with password provider failed: %v", err)
+	}
+	if asked != pdfPath {
+		t.Errorf("Expected password provider to be called with %s, got %q", pdfPath, asked)
+	}
+	if info.Pages != 1 || info.Encryption == "" {
+		t.Errorf("Expected 1 page with encryption recorded, got %d pages, encryption %q", info.Pages, info.Encryption)
+	}
+}
@@ -2,6 +2,7 @@
 package tifpdf2png
 
 import (
+	"errors"
 	"fmt"
 	"image"
 	"log/slog"
@@ -12,105 +13,211 @@ import (
 	"github.com/gen2brain/go-fitz"
 )
 
+// pdfRenderDPI is the resolution PDF pages are rasterized at
+const pdfRenderDPI = 300.0
+
 // ConvertPdfToPngWithImageDetails converts PDF to PNG and returns ImageDetail slice
 func ConvertPdfToPngWithImageDetails(pdfFilename string, destpath string, prefix string) ([]*ImageDetail, error) {
-	doc, err := fitz.New(pdfFilename)
+	return ConvertPdfToPngWithOptions(pdfFilename, destpath, prefix, nil)
+}
+
+// ConvertPdfToPngWithOptions converts PDF to PNG using the given options and returns ImageDetail slice
+func ConvertPdfToPngWithOptions(pdfFilename string, destpath string, prefix string, opts *Options) ([]*ImageDetail, error) {
+	imageDetails, _, err := convertPdf(fileSource(pdfFilename), destpath, prefix, opts, false)
+	return imageDetails, err
+}
+
+// ConvertPdfToPngWithDocumentInfo converts PDF to PNG and also returns the document's metadata,
+// outline, page labels and page sizes
+func ConvertPdfToPngWithDocumentInfo(pdfFilename string, destpath string, prefix string, opts *Options) ([]*ImageDetail, *DocumentInfo, error) {
+	return convertPdf(fileSource(pdfFilename), destpath, prefix, opts, true)
+}
+
+// ReadPdfDocumentInfo returns the metadata, outline, page labels and page sizes of a PDF
+// without rendering any pages. Only the password options are used.
+func ReadPdfDocumentInfo(pdfFilename string, opts *Options) (*DocumentInfo, error) {
+	doc, err := openPdf(fileSource(pdfFilename), opts.orDefault())
 	if err != nil {
-		slog.Error("ConvertPdfToPngWithImageDetails: load PDF file", "error", err)
+		slog.Error("ReadPdfDocumentInfo: load PDF file", "error", err)
 		return nil, err
 	}
-	defer func() {
-		if err := doc.Close(); err != nil {
-			slog.Warn("Failed to close PDF document", "error", err)
-		}
-	}()
+	defer doc.close()
+
+	return readDocumentInfo(doc, pdfFilename), nil
+}
+
+// convertPdf renders every page of a PDF, optionally collecting document info
+func convertPdf(src *source, destpath string, prefix string, opts *Options, withInfo bool) ([]*ImageDetail, *DocumentInfo, error) {
+	started := time.Now()
+	opts = opts.orDefault()
+	if err := opts.Validate(); err != nil {
+		slog.Error("ConvertPdfToPngWithOptions: invalid options", "error", err)
+		return nil, nil, err
+	}
+
+	doc, err := openPdf(src, opts)
+	if err != nil {
+		slog.Error("ConvertPdfToPngWithOptions: load PDF file", "error", err)
+		return nil, nil, err
+	}
+	defer doc.close()
 
 	pageCount := doc.NumPage()
 	if pageCount == 0 {
-		slog.Error("ConvertPdfToPngWithImageDetails: no pages found in PDF file")
-		return nil, fmt.Errorf("no pages found in PDF file")
+		slog.Error("ConvertPdfToPngWithOptions: no pages found in PDF file")
+		return nil, nil, fmt.Errorf("no pages found in PDF file")
+	}
+
+	var info *DocumentInfo
+	if withInfo {
+		info = readDocumentInfo(doc, src.path)
 	}
 
 	if prefix == "" {
 		prefix = time.Now().Format("20060102-150405-")
 	}
-	if destpath[len(destpath)-1:] != "/" {
-		destpath = destpath + "/"
-	}
+	sink := opts.sinkFor(destpath)
 
 	var imageDetails []*ImageDetail
 
 	for pageNum := 0; pageNum < pageCount; pageNum++ {
-		img, err := doc.Image(pageNum)
-		if err != nil {
-			slog.Error("ConvertPdfToPngWithImageDetails: render page",
+		if !opts.Pages.includes(pageNum + 1) {
+			continue
+		}
+		if opts.Format == FormatSVG || opts.Format == FormatHTML {
+			// Vector output is in points and never cropped
+			imageDetail, err := savePdfPageAsVector(doc.Document, pageNum, pageCount, sink, prefix, opts.Format)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := addPdfPageDetails(doc, pageNum, imageDetail, 1, CropInfo{}, opts); err != nil {
+				return nil, nil, err
+			}
+			if err := opts.pageSaved(imageDetail); err != nil {
+				return nil, nil, err
+			}
+			imageDetails = append(imageDetails, imageDetail)
+			continue
+		}
+
+		var img image.Image
+		scale := opts.renderDPI() / pdfPointsPerInch
+		var originX, originY int
+
+		var embedded *embeddedPageImage
+		if (opts.ExtractFullPageImage || doc.imagePages()) && !doc.rendersAnnotationsOn(pageNum) {
+			embedded = fullPageImage(doc.Document, pageNum)
+		}
+		if embedded != nil {
+			img, scale, originX, originY = embedded.img, embedded.scale, embedded.originX, embedded.originY
+			slog.Debug("ConvertPdfToPngWithOptions: extracted full-page image",
 				"page", pageNum,
-				"error", err)
-			return nil, err
+				"size", fmt.Sprintf("%dx%d", img.Bounds().Dx(), img.Bounds().Dy()))
+		} else {
+			img, err = doc.ImageDPI(pageNum, opts.renderDPI())
+			if err != nil {
+				slog.Error("ConvertPdfToPngWithOptions: render page",
+					"page", pageNum,
+					"error", err)
+				return nil, nil, err
+			}
 		}
 
 		if img == nil {
-			slog.Warn("ConvertPdfToPngWithImageDetails: nil image for page", "page", pageNum)
+			slog.Warn("ConvertPdfToPngWithOptions: nil image for page", "page", pageNum)
 			continue
 		}
 
-		var croppedFrame image.Image
-		var cropInfo CropInfo
+		whiteBackgroundFrame, cropInfo := normalizePage(img, opts)
 
-		croppedFrame, cropInfo = cropToContentWithInfo(img)
-		whiteBackgroundFrame := convertToWhiteBackground(croppedFrame)
+		// Page coordinates map onto the output through the crop and, for extracted
+		// images, the image's position on the page
+		placement := cropInfo
+		placement.OffsetX += originX
+		placement.OffsetY += originY
 
 		outputFilename := prefix + strconv.Itoa(pageNum) + ".png"
-		outputFilepath := destpath + outputFilename
-		err = saveImageAsPng(whiteBackgroundFrame, outputFilepath)
+		url, err := saveImageAsPng(sink, whiteBackgroundFrame, outputFilename)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		slog.Debug("Saved PDF page with crop info",
-			"filename", outputFilepath,
+			"filename", url,
 			"page", pageNum,
 			"offsetX", cropInfo.OffsetX,
 			"offsetY", cropInfo.OffsetY,
 			"originalSize", fmt.Sprintf("%dx%d", cropInfo.OriginalWidth, cropInfo.OriginalHeight),
 			"croppedSize", fmt.Sprintf("%dx%d", cropInfo.CroppedWidth, cropInfo.CroppedHeight))
 
-		var cropDetail *CropDetail
-		var imageWidth, imageHeight int
-
-		if cropInfo.CroppedWidth != cropInfo.OriginalWidth || cropInfo.CroppedHeight != cropInfo.OriginalHeight {
-			cropDetail = &CropDetail{
-				OffsetX:        cropInfo.OffsetX,
-				OffsetY:        cropInfo.OffsetY,
-				OriginalWidth:  cropInfo.OriginalWidth,
-				OriginalHeight: cropInfo.OriginalHeight,
-				CroppedWidth:   cropInfo.CroppedWidth,
-				CroppedHeight:  cropInfo.CroppedHeight,
-			}
-			imageWidth = cropInfo.CroppedWidth
-			imageHeight = cropInfo.CroppedHeight
-		} else {
-			cropDetail = nil
-			imageWidth = cropInfo.OriginalWidth
-			imageHeight = cropInfo.OriginalHeight
-		}
+		cropDetail, imageWidth, imageHeight := cropDetailFor(cropInfo)
 
 		imageDetail := &ImageDetail{
 			ActualType: "png",
 			Page:       pageNum + 1,
 			Pages:      pageCount,
-			URL:        filepath.Join(destpath, outputFilename),
+			URL:        url,
 			Width:      imageWidth,
 			Height:     imageHeight,
 			Format:     "png",
 			Quality:    95.0,
 			CropDetail: cropDetail,
+			Extracted:  embedded != nil,
+			DPI:        &Resolution{X: scale * pdfPointsPerInch, Y: scale * pdfPointsPerInch},
+		}
+
+		if err := addPdfPageDetails(doc, pageNum, imageDetail, scale, placement, opts); err != nil {
+			return nil, nil, err
+		}
+		if err := opts.pageSaved(imageDetail); err != nil {
+			return nil, nil, err
 		}
 
 		imageDetails = append(imageDetails, imageDetail)
 	}
 
-	return imageDetails, nil
+	if opts.WriteManifest {
+		if err := writeManifest(src, destpath, opts, pageCount, started, imageDetails); err != nil {
+			slog.Error("ConvertPdfToPngWithOptions: write manifest", "error", err)
+			return nil, nil, err
+		}
+	}
+
+	return imageDetails, info, nil
+}
+
+// addPdfPageDetails adds the text layer, annotations and links selected by the options
+// to a page's ImageDetail, mapping page coordinates with the given scale and placement
+func addPdfPageDetails(doc *pdfDocument, pageNum int, detail *ImageDetail, scale float64, placement CropInfo, opts *Options) error {
+	var err error
+
+	if opts.ExtractText {
+		detail.Text, err = extractPageText(doc.Document, pageNum, scale*pdfPointsPerInch, placement, opts.IncludeHTML)
+		if err != nil {
+			slog.Error("ConvertPdfToPngWithOptions: extract text",
+				"page", pageNum,
+				"error", err)
+			return err
+		}
+	}
+
+	if opts.ExtractAnnotations && pageNum < len(doc.annotations) {
+		for _, annotation := range doc.annotations[pageNum] {
+			detail.Annotations = append(detail.Annotations, annotation.toPixels(scale, placement))
+		}
+	}
+
+	if opts.ExtractLinks {
+		detail.Links, err = pageLinks(doc.Document, pageNum)
+		if err != nil {
+			slog.Error("ConvertPdfToPngWithOptions: extract links",
+				"page", pageNum,
+				"error", err)
+			return err
+		}
+	}
+
+	return nil
 }
 
 // ConvertPdfToPng provides a simplified interface that returns only filenames
@@ -156,3 +263,114 @@ func ConvertPdfToPngWithCropInfo(pdfFilename string, destpath string, prefix str
 
 	return result, nil
 }
+
+// pdfDocument is an opened PDF along with data gathered while opening it
+type pdfDocument struct {
+	*fitz.Document
+
+	// raw holds the decrypted or flattened PDF when the document was opened from
+	// memory, so raw structure scans see the same content as go-fitz
+	raw []byte
+
+	// decrypted is set when the document was decrypted with a password
+	decrypted bool
+
+	// annotations holds the annotations of each page when they were read
+	annotations [][]pdfAnnotation
+
+	// format is set for non-PDF documents opened through the same path
+	format *documentFormat
+}
+
+// openPdf opens a PDF with go-fitz, decrypting it in memory first if it is password
+// protected and flattening annotation appearances selected by the options
+func openPdf(src *source, opts *Options) (*pdfDocument, error) {
+	if format := documentFormats[src.ext]; format != nil {
+		doc, err := src.openFitz()
+		if err != nil {
+			return nil, err
+		}
+		if opts.ExtractAnnotations || opts.rendersAnnotations() {
+			slog.Debug("openPdf: annotations are only read from PDFs", "format", format.name)
+		}
+		return &pdfDocument{Document: doc, format: format}, nil
+	}
+
+	doc, err := src.openFitz()
+	if err != nil && !errors.Is(err, fitz.ErrNeedsPassword) {
+		return nil, err
+	}
+
+	pdf := &pdfDocument{Document: doc, raw: src.data}
+	if err != nil {
+		if err := doc.Close(); err != nil {
+			slog.Warn("Failed to close PDF document", "error", err)
+		}
+
+		password := opts.Password
+		if password == "" && opts.PasswordProvider != nil {
+			password, err = opts.PasswordProvider(src.path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if password == "" {
+			return nil, ErrEncrypted
+		}
+
+		pdf.raw, err = decryptPdf(src, password)
+		if err != nil {
+			return nil, err
+		}
+		pdf.decrypted = true
+		pdf.Document, err = fitz.NewFromMemory(pdf.raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.ExtractAnnotations || opts.rendersAnnotations() {
+		annotations, flattened, err := readPdfAnnotations(src.path, pdf.raw, opts)
+		if err != nil {
+			pdf.close()
+			return nil, err
+		}
+		pdf.annotations = annotations
+
+		if flattened != nil {
+			pdf.close()
+			pdf.raw = flattened
+			pdf.Document, err = fitz.NewFromMemory(flattened)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return pdf, nil
+}
+
+// imagePages reports whether every page of the document is a single image
+func (pdf *pdfDocument) imagePages() bool {
+	return pdf.format != nil && pdf.format.imagePages
+}
+
+// rendersAnnotationsOn reports whether annotation appearances were flattened into a page
+func (pdf *pdfDocument) rendersAnnotationsOn(pageNum int) bool {
+	if pageNum >= len(pdf.annotations) {
+		return false
+	}
+	for _, annotation := range pdf.annotations[pageNum] {
+		if annotation.Rendered {
+			return true
+		}
+	}
+	return false
+}
+
+// close closes the underlying go-fitz document, logging any error
+func (pdf *pdfDocument) close() {
+	if err := pdf.Close(); err != nil {
+		slog.Warn("Failed to close PDF document", "error", err)
+	}
+}
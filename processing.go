@@ -6,13 +6,42 @@ import (
 	"image/draw"
 	"image/png"
 	"log/slog"
-	"os"
 
 	"github.com/disintegration/imaging"
 )
 
+// defaultBinarizeThreshold is the luminance below which pixels become black content
+const defaultBinarizeThreshold = 128
+
+// normalizePage crops an image to its content and converts it to black on white, as
+// selected by the options, and returns the crop information
+func normalizePage(img image.Image, opts *Options) (image.Image, CropInfo) {
+	bounds := img.Bounds()
+	normalized, cropInfo := img, CropInfo{
+		OriginalWidth:  bounds.Dx(),
+		OriginalHeight: bounds.Dy(),
+		CroppedWidth:   bounds.Dx(),
+		CroppedHeight:  bounds.Dy(),
+	}
+	if !opts.NoCrop {
+		normalized, cropInfo = cropToContentWithInfo(img)
+	}
+	if !opts.NoBinarize {
+		normalized = convertToWhiteBackground(normalized, opts.threshold())
+	}
+	return normalized, cropInfo
+}
+
 // cropToContentWithInfo crops an image to its content boundaries and returns crop information
 func cropToContentWithInfo(img image.Image) (image.Image, CropInfo) {
+	content, cropInfo := contentBoundsWithInfo(img)
+	croppedImg := imaging.Crop(img, content)
+	return croppedImg, cropInfo
+}
+
+// contentBoundsWithInfo returns the boundaries of the non-transparent content of an image
+// and the matching crop information
+func contentBoundsWithInfo(img image.Image) (image.Rectangle, CropInfo) {
 	bounds := img.Bounds()
 	minX, minY, maxX, maxY := bounds.Max.X, bounds.Max.Y, bounds.Min.X, bounds.Min.Y
 
@@ -45,12 +74,48 @@ func cropToContentWithInfo(img image.Image) (image.Image, CropInfo) {
 		CroppedHeight:  maxY + 1 - minY,
 	}
 
-	croppedImg := imaging.Crop(img, image.Rect(minX, minY, maxX+1, maxY+1))
-	return croppedImg, cropInfo
+	return image.Rect(minX, minY, maxX+1, maxY+1), cropInfo
 }
 
-// convertToWhiteBackground ensures the image has a white background with black content
-func convertToWhiteBackground(src image.Image) image.Image {
+// cropDetailFor returns the crop detail and output size for a crop, with a nil detail
+// when the image was not cropped
+func cropDetailFor(cropInfo CropInfo) (*CropDetail, int, int) {
+	if cropInfo.CroppedWidth == cropInfo.OriginalWidth && cropInfo.CroppedHeight == cropInfo.OriginalHeight {
+		return nil, cropInfo.OriginalWidth, cropInfo.OriginalHeight
+	}
+	return &CropDetail{
+		OffsetX:        cropInfo.OffsetX,
+		OffsetY:        cropInfo.OffsetY,
+		OriginalWidth:  cropInfo.OriginalWidth,
+		OriginalHeight: cropInfo.OriginalHeight,
+		CroppedWidth:   cropInfo.CroppedWidth,
+		CroppedHeight:  cropInfo.CroppedHeight,
+	}, cropInfo.CroppedWidth, cropInfo.CroppedHeight
+}
+
+// convertToWhiteBackground ensures the image has a white background with black content,
+// treating pixels darker than threshold as content
+func convertToWhiteBackground(src image.Image, threshold uint8) image.Image {
+	bounds := src.Bounds()
+	shouldInvert := hasDarkBackground(src)
+
+	dst := image.NewRGBA(bounds)
+	white := color.RGBA{255, 255, 255, 255}
+
+	draw.Draw(dst, bounds, &image.Uniform{white}, image.Point{}, draw.Src)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			dst.Set(x, y, binarizePixel(src.At(x, y), shouldInvert, threshold))
+		}
+	}
+
+	return dst
+}
+
+// hasDarkBackground samples the corners of an image, or a coarse grid if the corners are
+// transparent, and reports whether most sampled pixels are dark
+func hasDarkBackground(src image.Image) bool {
 	bounds := src.Bounds()
 
 	darkPixelCount := 0
@@ -120,54 +185,47 @@ func convertToWhiteBackground(src image.Image) image.Image {
 		"darkRatio", float64(darkPixelCount)/float64(sampledPixels),
 		"shouldInvert", shouldInvert)
 
-	dst := image.NewRGBA(bounds)
+	return shouldInvert
+}
+
+// binarizePixel maps a pixel to black content or white background, inverting dark backgrounds
+func binarizePixel(c color.Color, invert bool, threshold uint8) color.RGBA {
 	white := color.RGBA{255, 255, 255, 255}
 	black := color.RGBA{0, 0, 0, 255}
 
-	draw.Draw(dst, bounds, &image.Uniform{white}, image.Point{}, draw.Src)
-
-	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-		for x := bounds.Min.X; x < bounds.Max.X; x++ {
-			r, g, b, a := src.At(x, y).RGBA()
-			r8, g8, b8, a8 := uint8(r>>8), uint8(g>>8), uint8(b>>8), uint8(a>>8)
+	r, g, b, a := c.RGBA()
+	r8, g8, b8, a8 := uint8(r>>8), uint8(g>>8), uint8(b>>8), uint8(a>>8)
 
-			if a8 == 0 {
-				dst.Set(x, y, white)
-				continue
-			}
+	if a8 == 0 {
+		return white
+	}
 
-			luminance := 0.299*float64(r8) + 0.587*float64(g8) + 0.114*float64(b8)
+	luminance := 0.299*float64(r8) + 0.587*float64(g8) + 0.114*float64(b8)
 
-			if shouldInvert {
-				if luminance < 128 {
-					dst.Set(x, y, white)
-				} else {
-					dst.Set(x, y, black)
-				}
-			} else {
-				if luminance < 128 {
-					dst.Set(x, y, black)
-				} else {
-					dst.Set(x, y, white)
-				}
-			}
+	if invert {
+		if luminance < float64(threshold) {
+			return white
 		}
+		return black
 	}
-
-	return dst
+	if luminance < float64(threshold) {
+		return black
+	}
+	return white
 }
 
-// saveImageAsPng saves an image to a PNG file
-func saveImageAsPng(img image.Image, filename string) error {
-	file, err := os.Create(filename)
+// saveImageAsPng saves an image as a PNG file of a sink and returns its URL
+func saveImageAsPng(sink PageSink, img image.Image, name string) (string, error) {
+	file, url, err := sink.Create(name)
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer func() {
+
+	if err := png.Encode(file, img); err != nil {
 		if err := file.Close(); err != nil {
 			slog.Warn("Failed to close PNG file", "error", err)
 		}
-	}()
-
-	return png.Encode(file, img)
+		return "", err
+	}
+	return url, file.Close()
 }
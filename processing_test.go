@@ -1,10 +1,15 @@
 package tifpdf2png
 
 import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -53,6 +58,268 @@ func downloadTestFile(t *testing.T, url, destPath string) error {
 	return err
 }
 
+// writeTestPdf writes a minimal PDF with one Letter-sized page per entry in pageTexts,
+// each showing its text in Helvetica at 24pt, 72pt from the top-left corner
+func writeTestPdf(t *testing.T, destPath string, pageTexts []string) {
+	t.Helper()
+
+	var objects []string
+	kids := make([]string, len(pageTexts))
+	for i, text := range pageTexts {
+		pageObj := 4 + 2*i
+		kids[i] = fmt.Sprintf("%d 0 R", pageObj)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents %d 0 R /Resources << /Font << /F1 3 0 R >> >> >>", pageObj+1),
+			testPdfStream("", fmt.Sprintf("BT /F1 24 Tf 72 696 Td (%s) Tj ET", text)),
+		)
+	}
+	objects = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pageTexts)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}, objects...)
+
+	writeRawTestPdf(t, destPath, objects)
+}
+
+// testPdfStream formats a PDF stream object with extra dictionary entries
+func testPdfStream(entries string, content string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", entries, len(content), content)
+}
+
+// writeRawTestPdf writes a PDF consisting of the given objects, numbered from 1, with
+// object 1 as the document catalog
+func writeRawTestPdf(t *testing.T, destPath string, objects []string) {
+	t.Helper()
+
+	var b strings.Builder
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	if err := os.WriteFile(destPath, []byte(b.String()), 0644); err != nil {
+		t.Fatalf("Failed to write test PDF: %v", err)
+	}
+}
+
+// TIFF field types used by test TIFFs
+const (
+	tiffASCII     = 2
+	tiffShort     = 3
+	tiffLong      = 4
+	tiffRational  = 5
+	tiffUndefined = 7
+	tiffDouble    = 12
+)
+
+// testTiffTag is an IFD entry of a test TIFF. Values are a string, []byte, []uint16,
+// []uint32, [][2]uint32 or []float64 matching the field type.
+type testTiffTag struct {
+	typ    uint16
+	values any
+}
+
+// testTiffPage is an uncompressed 8-bit page of a test TIFF, grayscale (BlackIsZero)
+// or RGB depending on the number of samples in pixels
+type testTiffPage struct {
+	width, height int
+	pixels        []byte
+	tileSize      int                    // Write square tiles of this size instead of a single strip
+	tags          map[uint16]testTiffTag // Extra or overriding tags
+	subImages     []testTiffPage         // Written as SubIFDs of the page
+}
+
+// writeRawTestTiff writes a little-endian classic TIFF with one IFD per page
+func writeRawTestTiff(t *testing.T, destPath string, pages []testTiffPage) {
+	t.Helper()
+	writeTestTiffFile(t, destPath, pages, false)
+}
+
+// writeRawTestBigTiff writes a little-endian BigTIFF with one IFD per page
+func writeRawTestBigTiff(t *testing.T, destPath string, pages []testTiffPage) {
+	t.Helper()
+	writeTestTiffFile(t, destPath, pages, true)
+}
+
+// writeTestTiffFile writes a classic TIFF or a BigTIFF with one IFD per page
+func writeTestTiffFile(t *testing.T, destPath string, pages []testTiffPage, big bool) {
+	t.Helper()
+
+	var b bytes.Buffer
+	nextPos := 4
+	if big {
+		b.Write([]byte{'I', 'I', 43, 0, 8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0})
+		nextPos = 8
+	} else {
+		b.Write([]byte{'I', 'I', 42, 0, 0, 0, 0, 0})
+	}
+
+	for _, page := range pages {
+		offset, pos := writeTestTiffIFD(&b, page, big)
+		if big {
+			binary.LittleEndian.PutUint64(b.Bytes()[nextPos:], uint64(offset))
+		} else {
+			binary.LittleEndian.PutUint32(b.Bytes()[nextPos:], uint32(offset))
+		}
+		nextPos = pos
+	}
+
+	if err := os.WriteFile(destPath, b.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write test TIFF: %v", err)
+	}
+}
+
+// writeTestTiffIFD appends a page's data and IFD, returning the IFD offset and the
+// position of its next IFD pointer
+func writeTestTiffIFD(b *bytes.Buffer, page testTiffPage, big bool) (int, int) {
+	var subOffsets []uint32
+	for _, sub := range page.subImages {
+		offset, _ := writeTestTiffIFD(b, sub, big)
+		subOffsets = append(subOffsets, uint32(offset))
+	}
+
+	samples := len(page.pixels) / (page.width * page.height)
+	photometric := uint16(1)
+	if samples == 3 {
+		photometric = 2
+	}
+	bits := make([]uint16, samples)
+	for i := range bits {
+		bits[i] = 8
+	}
+
+	tags := map[uint16]testTiffTag{
+		256: {tiffLong, []uint32{uint32(page.width)}},
+		257: {tiffLong, []uint32{uint32(page.height)}},
+		258: {tiffShort, bits},
+		259: {tiffShort, []uint16{1}},
+		262: {tiffShort, []uint16{photometric}},
+		277: {tiffShort, []uint16{uint16(samples)}},
+	}
+
+	if page.tileSize > 0 {
+		var offsets, counts []uint32
+		size := page.tileSize
+		for ty := 0; ty < page.height; ty += size {
+			for tx := 0; tx < page.width; tx += size {
+				offsets = append(offsets, uint32(b.Len()))
+				for y := ty; y < ty+size; y++ {
+					for x := tx; x < tx+size; x++ {
+						if x < page.width && y < page.height {
+							i := (y*page.width + x) * samples
+							b.Write(page.pixels[i : i+samples])
+						} else {
+							b.Write(make([]byte, samples))
+						}
+					}
+				}
+				counts = append(counts, uint32(size*size*samples))
+			}
+		}
+		tags[322] = testTiffTag{tiffLong, []uint32{uint32(size)}}
+		tags[323] = testTiffTag{tiffLong, []uint32{uint32(size)}}
+		tags[324] = testTiffTag{tiffLong, offsets}
+		tags[325] = testTiffTag{tiffLong, counts}
+	} else {
+		tags[273] = testTiffTag{tiffLong, []uint32{uint32(b.Len())}}
+		tags[278] = testTiffTag{tiffLong, []uint32{uint32(page.height)}}
+		tags[279] = testTiffTag{tiffLong, []uint32{uint32(len(page.pixels))}}
+		b.Write(page.pixels)
+	}
+	if b.Len()%2 == 1 {
+		b.WriteByte(0)
+	}
+
+	if len(subOffsets) > 0 {
+		tags[330] = testTiffTag{tiffLong, subOffsets}
+	}
+	for tag, value := range page.tags {
+		tags[tag] = value
+	}
+
+	ids := make([]int, 0, len(tags))
+	for tag := range tags {
+		ids = append(ids, int(tag))
+	}
+	sort.Ints(ids)
+
+	valueSize := 4
+	if big {
+		valueSize = 8
+	}
+
+	var entries bytes.Buffer
+	for _, id := range ids {
+		tag := tags[uint16(id)]
+
+		var data bytes.Buffer
+		count := 0
+		switch v := tag.values.(type) {
+		case string:
+			data.WriteString(v + "\x00")
+			count = len(v) + 1
+		case []byte:
+			data.Write(v)
+			count = len(v)
+		case []uint16:
+			_ = binary.Write(&data, binary.LittleEndian, v)
+			count = len(v)
+		case []uint32:
+			_ = binary.Write(&data, binary.LittleEndian, v)
+			count = len(v)
+		case [][2]uint32:
+			_ = binary.Write(&data, binary.LittleEndian, v)
+			count = len(v)
+		case []float64:
+			_ = binary.Write(&data, binary.LittleEndian, v)
+			count = len(v)
+		}
+
+		value := make([]byte, valueSize)
+		if data.Len() > valueSize {
+			if big {
+				binary.LittleEndian.PutUint64(value, uint64(b.Len()))
+			} else {
+				binary.LittleEndian.PutUint32(value, uint32(b.Len()))
+			}
+			b.Write(data.Bytes())
+			if b.Len()%2 == 1 {
+				b.WriteByte(0)
+			}
+		} else {
+			copy(value, data.Bytes())
+		}
+
+		_ = binary.Write(&entries, binary.LittleEndian, []uint16{uint16(id), tag.typ})
+		if big {
+			_ = binary.Write(&entries, binary.LittleEndian, uint64(count))
+		} else {
+			_ = binary.Write(&entries, binary.LittleEndian, uint32(count))
+		}
+		entries.Write(value)
+	}
+
+	offset := b.Len()
+	if big {
+		_ = binary.Write(b, binary.LittleEndian, uint64(len(ids)))
+	} else {
+		_ = binary.Write(b, binary.LittleEndian, uint16(len(ids)))
+	}
+	b.Write(entries.Bytes())
+	nextPos := b.Len()
+	b.Write(make([]byte, valueSize))
+	return offset, nextPos
+}
+
 func TestConvertTiffToPngWithImageDetails(t *testing.T) {
 	testTiffPath := filepath.Join("testdata", "UTM2GTIF.TIF")
 
@@ -0,0 +1,1882 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: tifpdf2png/v1/tifpdf2png.proto
+
+package tifpdf2pngv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ConvertRequest is a document to convert, whose format is detected from its content
+type ConvertRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Document      []byte                 `protobuf:"bytes,1,opt,name=document,proto3" json:"document,omitempty"`                              // Contents of the document
+	Options       *ConvertOptions        `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`                                // Conversion options; unset fields keep the server's defaults
+	DocumentInfo  bool                   `protobuf:"varint,3,opt,name=document_info,json=documentInfo,proto3" json:"document_info,omitempty"` // Also return the metadata, outline and page sizes of PDFs and documents
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertRequest) Reset() {
+	*x = ConvertRequest{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertRequest) ProtoMessage() {}
+
+func (x *ConvertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertRequest.ProtoReflect.Descriptor instead.
+func (*ConvertRequest) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ConvertRequest) GetDocument() []byte {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetOptions() *ConvertOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *ConvertRequest) GetDocumentInfo() bool {
+	if x != nil {
+		return x.DocumentInfo
+	}
+	return false
+}
+
+// ConvertOptions mirrors the per-request fields of the library's Options
+type ConvertOptions struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Format             string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`                                                    // Output format for PDF pages: png, svg or html
+	Dpi                float64                `protobuf:"fixed64,2,opt,name=dpi,proto3" json:"dpi,omitempty"`                                                        // Resolution document pages are rendered at
+	Pages              string                 `protobuf:"bytes,3,opt,name=pages,proto3" json:"pages,omitempty"`                                                      // Pages to convert, such as "1-3,5,8-"
+	NoCrop             bool                   `protobuf:"varint,4,opt,name=no_crop,json=noCrop,proto3" json:"no_crop,omitempty"`                                     // Keep whole pages instead of cropping them to their content
+	NoBinarize         bool                   `protobuf:"varint,5,opt,name=no_binarize,json=noBinarize,proto3" json:"no_binarize,omitempty"`                         // Keep page colours instead of converting pages to black on white
+	Threshold          uint32                 `protobuf:"varint,6,opt,name=threshold,proto3" json:"threshold,omitempty"`                                             // Luminance (1-255) below which pixels become black when binarizing
+	ExtractText        bool                   `protobuf:"varint,7,opt,name=extract_text,json=extractText,proto3" json:"extract_text,omitempty"`                      // Extract the PDF text layer and word boxes
+	ExtractLinks       bool                   `protobuf:"varint,8,opt,name=extract_links,json=extractLinks,proto3" json:"extract_links,omitempty"`                   // List the URIs of page links
+	ExtractAnnotations bool                   `protobuf:"varint,9,opt,name=extract_annotations,json=extractAnnotations,proto3" json:"extract_annotations,omitempty"` // List annotations and form fields
+	Password           string                 `protobuf:"bytes,10,opt,name=password,proto3" json:"password,omitempty"`                                               // Password for encrypted PDFs
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ConvertOptions) Reset() {
+	*x = ConvertOptions{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertOptions) ProtoMessage() {}
+
+func (x *ConvertOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertOptions.ProtoReflect.Descriptor instead.
+func (*ConvertOptions) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ConvertOptions) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ConvertOptions) GetDpi() float64 {
+	if x != nil {
+		return x.Dpi
+	}
+	return 0
+}
+
+func (x *ConvertOptions) GetPages() string {
+	if x != nil {
+		return x.Pages
+	}
+	return ""
+}
+
+func (x *ConvertOptions) GetNoCrop() bool {
+	if x != nil {
+		return x.NoCrop
+	}
+	return false
+}
+
+func (x *ConvertOptions) GetNoBinarize() bool {
+	if x != nil {
+		return x.NoBinarize
+	}
+	return false
+}
+
+func (x *ConvertOptions) GetThreshold() uint32 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+func (x *ConvertOptions) GetExtractText() bool {
+	if x != nil {
+		return x.ExtractText
+	}
+	return false
+}
+
+func (x *ConvertOptions) GetExtractLinks() bool {
+	if x != nil {
+		return x.ExtractLinks
+	}
+	return false
+}
+
+func (x *ConvertOptions) GetExtractAnnotations() bool {
+	if x != nil {
+		return x.ExtractAnnotations
+	}
+	return false
+}
+
+func (x *ConvertOptions) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+// ConvertResponse holds the converted pages of a document
+type ConvertResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Pages         []*Page                `protobuf:"bytes,1,rep,name=pages,proto3" json:"pages,omitempty"`
+	Document      *DocumentInfo          `protobuf:"bytes,2,opt,name=document,proto3" json:"document,omitempty"` // Set when document_info was requested, for PDFs and documents
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertResponse) Reset() {
+	*x = ConvertResponse{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertResponse) ProtoMessage() {}
+
+func (x *ConvertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertResponse.ProtoReflect.Descriptor instead.
+func (*ConvertResponse) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConvertResponse) GetPages() []*Page {
+	if x != nil {
+		return x.Pages
+	}
+	return nil
+}
+
+func (x *ConvertResponse) GetDocument() *DocumentInfo {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+// ConvertStreamResponse is a converted page or, last, the document info
+type ConvertStreamResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Result:
+	//
+	//	*ConvertStreamResponse_Page
+	//	*ConvertStreamResponse_Document
+	Result        isConvertStreamResponse_Result `protobuf_oneof:"result"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConvertStreamResponse) Reset() {
+	*x = ConvertStreamResponse{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConvertStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConvertStreamResponse) ProtoMessage() {}
+
+func (x *ConvertStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConvertStreamResponse.ProtoReflect.Descriptor instead.
+func (*ConvertStreamResponse) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ConvertStreamResponse) GetResult() isConvertStreamResponse_Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ConvertStreamResponse) GetPage() *Page {
+	if x != nil {
+		if x, ok := x.Result.(*ConvertStreamResponse_Page); ok {
+			return x.Page
+		}
+	}
+	return nil
+}
+
+func (x *ConvertStreamResponse) GetDocument() *DocumentInfo {
+	if x != nil {
+		if x, ok := x.Result.(*ConvertStreamResponse_Document); ok {
+			return x.Document
+		}
+	}
+	return nil
+}
+
+type isConvertStreamResponse_Result interface {
+	isConvertStreamResponse_Result()
+}
+
+type ConvertStreamResponse_Page struct {
+	Page *Page `protobuf:"bytes,1,opt,name=page,proto3,oneof"`
+}
+
+type ConvertStreamResponse_Document struct {
+	Document *DocumentInfo `protobuf:"bytes,2,opt,name=document,proto3,oneof"`
+}
+
+func (*ConvertStreamResponse_Page) isConvertStreamResponse_Result() {}
+
+func (*ConvertStreamResponse_Document) isConvertStreamResponse_Result() {}
+
+// Page is a converted page and the contents of its output file
+type Page struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Detail        *ImageDetail           `protobuf:"bytes,1,opt,name=detail,proto3" json:"detail,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"` // Contents of the PNG, SVG or HTML file named by detail.url
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Page) Reset() {
+	*x = Page{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Page) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Page) ProtoMessage() {}
+
+func (x *Page) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Page.ProtoReflect.Descriptor instead.
+func (*Page) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Page) GetDetail() *ImageDetail {
+	if x != nil {
+		return x.Detail
+	}
+	return nil
+}
+
+func (x *Page) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// ImageDetail contains detailed information about a converted image page
+type ImageDetail struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ActualType    string                 `protobuf:"bytes,1,opt,name=actual_type,json=actualType,proto3" json:"actual_type,omitempty"`       // The actual type of the image (e.g., "png")
+	SourceFormat  string                 `protobuf:"bytes,2,opt,name=source_format,json=sourceFormat,proto3" json:"source_format,omitempty"` // Format of a raster image input (e.g., "jpeg", "webp")
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`                                    // Page number (1-based)
+	Pages         int32                  `protobuf:"varint,4,opt,name=pages,proto3" json:"pages,omitempty"`                                  // Total number of pages
+	SubImage      int32                  `protobuf:"varint,5,opt,name=sub_image,json=subImage,proto3" json:"sub_image,omitempty"`            // Index of the TIFF sub-image within the page (0 is the main image)
+	Url           string                 `protobuf:"bytes,6,opt,name=url,proto3" json:"url,omitempty"`                                       // Name of the output file
+	Width         int32                  `protobuf:"varint,7,opt,name=width,proto3" json:"width,omitempty"`                                  // Width of the image in pixels
+	Height        int32                  `protobuf:"varint,8,opt,name=height,proto3" json:"height,omitempty"`                                // Height of the image in pixels
+	Format        string                 `protobuf:"bytes,9,opt,name=format,proto3" json:"format,omitempty"`                                 // Image format (e.g., "png")
+	Quality       float64                `protobuf:"fixed64,10,opt,name=quality,proto3" json:"quality,omitempty"`                            // Quality metric (0-100)
+	CropDetail    *CropDetail            `protobuf:"bytes,11,opt,name=crop_detail,json=cropDetail,proto3" json:"crop_detail,omitempty"`      // Crop information if cropping occurred
+	Text          *PageText              `protobuf:"bytes,12,opt,name=text,proto3" json:"text,omitempty"`                                    // Text layer of the page if extraction was requested
+	Annotations   []*Annotation          `protobuf:"bytes,13,rep,name=annotations,proto3" json:"annotations,omitempty"`                      // Annotations and form widgets if extraction was requested
+	Links         []string               `protobuf:"bytes,14,rep,name=links,proto3" json:"links,omitempty"`                                  // URIs of the page's links if extraction was requested
+	Extracted     bool                   `protobuf:"varint,15,opt,name=extracted,proto3" json:"extracted,omitempty"`                         // Page image was extracted at native resolution rather than rendered
+	Tiff          *TiffPageInfo          `protobuf:"bytes,16,opt,name=tiff,proto3" json:"tiff,omitempty"`                                    // Tags of the source TIFF page
+	SourceDpi     *Resolution            `protobuf:"bytes,17,opt,name=source_dpi,json=sourceDpi,proto3" json:"source_dpi,omitempty"`         // Resolution of the source page, if known
+	Dpi           *Resolution            `protobuf:"bytes,18,opt,name=dpi,proto3" json:"dpi,omitempty"`                                      // Resolution of the output image, if known
+	Geo           *GeoInfo               `protobuf:"bytes,19,opt,name=geo,proto3" json:"geo,omitempty"`                                      // Georeferencing of GeoTIFF pages
+	Transforms    []string               `protobuf:"bytes,20,rep,name=transforms,proto3" json:"transforms,omitempty"`                        // Orientation and colour transforms applied to the source image, in order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImageDetail) Reset() {
+	*x = ImageDetail{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImageDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImageDetail) ProtoMessage() {}
+
+func (x *ImageDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImageDetail.ProtoReflect.Descriptor instead.
+func (*ImageDetail) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ImageDetail) GetActualType() string {
+	if x != nil {
+		return x.ActualType
+	}
+	return ""
+}
+
+func (x *ImageDetail) GetSourceFormat() string {
+	if x != nil {
+		return x.SourceFormat
+	}
+	return ""
+}
+
+func (x *ImageDetail) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *ImageDetail) GetPages() int32 {
+	if x != nil {
+		return x.Pages
+	}
+	return 0
+}
+
+func (x *ImageDetail) GetSubImage() int32 {
+	if x != nil {
+		return x.SubImage
+	}
+	return 0
+}
+
+func (x *ImageDetail) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *ImageDetail) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ImageDetail) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ImageDetail) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ImageDetail) GetQuality() float64 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+func (x *ImageDetail) GetCropDetail() *CropDetail {
+	if x != nil {
+		return x.CropDetail
+	}
+	return nil
+}
+
+func (x *ImageDetail) GetText() *PageText {
+	if x != nil {
+		return x.Text
+	}
+	return nil
+}
+
+func (x *ImageDetail) GetAnnotations() []*Annotation {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+func (x *ImageDetail) GetLinks() []string {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *ImageDetail) GetExtracted() bool {
+	if x != nil {
+		return x.Extracted
+	}
+	return false
+}
+
+func (x *ImageDetail) GetTiff() *TiffPageInfo {
+	if x != nil {
+		return x.Tiff
+	}
+	return nil
+}
+
+func (x *ImageDetail) GetSourceDpi() *Resolution {
+	if x != nil {
+		return x.SourceDpi
+	}
+	return nil
+}
+
+func (x *ImageDetail) GetDpi() *Resolution {
+	if x != nil {
+		return x.Dpi
+	}
+	return nil
+}
+
+func (x *ImageDetail) GetGeo() *GeoInfo {
+	if x != nil {
+		return x.Geo
+	}
+	return nil
+}
+
+func (x *ImageDetail) GetTransforms() []string {
+	if x != nil {
+		return x.Transforms
+	}
+	return nil
+}
+
+// CropDetail contains information about how an image was cropped
+type CropDetail struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OffsetX        int32                  `protobuf:"varint,1,opt,name=offset_x,json=offsetX,proto3" json:"offset_x,omitempty"`                      // X offset of the crop from original
+	OffsetY        int32                  `protobuf:"varint,2,opt,name=offset_y,json=offsetY,proto3" json:"offset_y,omitempty"`                      // Y offset of the crop from original
+	OriginalWidth  int32                  `protobuf:"varint,3,opt,name=original_width,json=originalWidth,proto3" json:"original_width,omitempty"`    // Original width before cropping
+	OriginalHeight int32                  `protobuf:"varint,4,opt,name=original_height,json=originalHeight,proto3" json:"original_height,omitempty"` // Original height before cropping
+	CroppedWidth   int32                  `protobuf:"varint,5,opt,name=cropped_width,json=croppedWidth,proto3" json:"cropped_width,omitempty"`       // Width after cropping
+	CroppedHeight  int32                  `protobuf:"varint,6,opt,name=cropped_height,json=croppedHeight,proto3" json:"cropped_height,omitempty"`    // Height after cropping
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CropDetail) Reset() {
+	*x = CropDetail{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CropDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CropDetail) ProtoMessage() {}
+
+func (x *CropDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CropDetail.ProtoReflect.Descriptor instead.
+func (*CropDetail) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CropDetail) GetOffsetX() int32 {
+	if x != nil {
+		return x.OffsetX
+	}
+	return 0
+}
+
+func (x *CropDetail) GetOffsetY() int32 {
+	if x != nil {
+		return x.OffsetY
+	}
+	return 0
+}
+
+func (x *CropDetail) GetOriginalWidth() int32 {
+	if x != nil {
+		return x.OriginalWidth
+	}
+	return 0
+}
+
+func (x *CropDetail) GetOriginalHeight() int32 {
+	if x != nil {
+		return x.OriginalHeight
+	}
+	return 0
+}
+
+func (x *CropDetail) GetCroppedWidth() int32 {
+	if x != nil {
+		return x.CroppedWidth
+	}
+	return 0
+}
+
+func (x *CropDetail) GetCroppedHeight() int32 {
+	if x != nil {
+		return x.CroppedHeight
+	}
+	return 0
+}
+
+// Resolution is a horizontal and vertical resolution in dots per inch
+type Resolution struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             float64                `protobuf:"fixed64,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64                `protobuf:"fixed64,2,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Resolution) Reset() {
+	*x = Resolution{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Resolution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resolution) ProtoMessage() {}
+
+func (x *Resolution) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resolution.ProtoReflect.Descriptor instead.
+func (*Resolution) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Resolution) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Resolution) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+// PageText contains the text layer extracted from a PDF page
+type PageText struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"` // Plain text of the page
+	Html          string                 `protobuf:"bytes,2,opt,name=html,proto3" json:"html,omitempty"`       // Positioned HTML as produced by MuPDF
+	Words         []*WordBox             `protobuf:"bytes,3,rep,name=words,proto3" json:"words,omitempty"`     // Words with bounding boxes in output pixel coordinates
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PageText) Reset() {
+	*x = PageText{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PageText) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PageText) ProtoMessage() {}
+
+func (x *PageText) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PageText.ProtoReflect.Descriptor instead.
+func (*PageText) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PageText) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *PageText) GetHtml() string {
+	if x != nil {
+		return x.Html
+	}
+	return ""
+}
+
+func (x *PageText) GetWords() []*WordBox {
+	if x != nil {
+		return x.Words
+	}
+	return nil
+}
+
+// WordBox contains a word of the text layer and its bounding box in output pixels
+type WordBox struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	X             int32                  `protobuf:"varint,2,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"varint,3,opt,name=y,proto3" json:"y,omitempty"`
+	Width         int32                  `protobuf:"varint,4,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`
+	Estimated     bool                   `protobuf:"varint,6,opt,name=estimated,proto3" json:"estimated,omitempty"` // x and width are estimated, as for invisible OCR text, instead of measured from glyphs
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WordBox) Reset() {
+	*x = WordBox{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WordBox) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WordBox) ProtoMessage() {}
+
+func (x *WordBox) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WordBox.ProtoReflect.Descriptor instead.
+func (*WordBox) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WordBox) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *WordBox) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *WordBox) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *WordBox) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *WordBox) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *WordBox) GetEstimated() bool {
+	if x != nil {
+		return x.Estimated
+	}
+	return false
+}
+
+// Annotation describes a PDF annotation or form field widget on a page, with its
+// rectangle in output pixels
+type Annotation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // Annotation subtype (e.g., "Highlight", "Widget")
+	X             int32                  `protobuf:"varint,2,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"varint,3,opt,name=y,proto3" json:"y,omitempty"`
+	Width         int32                  `protobuf:"varint,4,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,5,opt,name=height,proto3" json:"height,omitempty"`
+	Contents      string                 `protobuf:"bytes,6,opt,name=contents,proto3" json:"contents,omitempty"`                       // Text contents of the annotation
+	FieldName     string                 `protobuf:"bytes,7,opt,name=field_name,json=fieldName,proto3" json:"field_name,omitempty"`    // Fully qualified form field name (widgets only)
+	FieldType     string                 `protobuf:"bytes,8,opt,name=field_type,json=fieldType,proto3" json:"field_type,omitempty"`    // Form field type: Tx, Btn, Ch or Sig (widgets only)
+	FieldValue    string                 `protobuf:"bytes,9,opt,name=field_value,json=fieldValue,proto3" json:"field_value,omitempty"` // Form field value (widgets only)
+	Hidden        bool                   `protobuf:"varint,10,opt,name=hidden,proto3" json:"hidden,omitempty"`                         // Annotation is flagged as hidden or not viewable
+	Rendered      bool                   `protobuf:"varint,11,opt,name=rendered,proto3" json:"rendered,omitempty"`                     // Annotation appearance was drawn onto the page image
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Annotation) Reset() {
+	*x = Annotation{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Annotation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Annotation) ProtoMessage() {}
+
+func (x *Annotation) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Annotation.ProtoReflect.Descriptor instead.
+func (*Annotation) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Annotation) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Annotation) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Annotation) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Annotation) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Annotation) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *Annotation) GetContents() string {
+	if x != nil {
+		return x.Contents
+	}
+	return ""
+}
+
+func (x *Annotation) GetFieldName() string {
+	if x != nil {
+		return x.FieldName
+	}
+	return ""
+}
+
+func (x *Annotation) GetFieldType() string {
+	if x != nil {
+		return x.FieldType
+	}
+	return ""
+}
+
+func (x *Annotation) GetFieldValue() string {
+	if x != nil {
+		return x.FieldValue
+	}
+	return ""
+}
+
+func (x *Annotation) GetHidden() bool {
+	if x != nil {
+		return x.Hidden
+	}
+	return false
+}
+
+func (x *Annotation) GetRendered() bool {
+	if x != nil {
+		return x.Rendered
+	}
+	return false
+}
+
+// TiffPageInfo contains the descriptive tags of a TIFF page
+type TiffPageInfo struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Compression    string                 `protobuf:"bytes,1,opt,name=compression,proto3" json:"compression,omitempty"`
+	Photometric    string                 `protobuf:"bytes,2,opt,name=photometric,proto3" json:"photometric,omitempty"`
+	XResolution    float64                `protobuf:"fixed64,3,opt,name=x_resolution,json=xResolution,proto3" json:"x_resolution,omitempty"`
+	YResolution    float64                `protobuf:"fixed64,4,opt,name=y_resolution,json=yResolution,proto3" json:"y_resolution,omitempty"`
+	ResolutionUnit string                 `protobuf:"bytes,5,opt,name=resolution_unit,json=resolutionUnit,proto3" json:"resolution_unit,omitempty"`
+	DateTime       string                 `protobuf:"bytes,6,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
+	Software       string                 `protobuf:"bytes,7,opt,name=software,proto3" json:"software,omitempty"`
+	Make           string                 `protobuf:"bytes,8,opt,name=make,proto3" json:"make,omitempty"`
+	Model          string                 `protobuf:"bytes,9,opt,name=model,proto3" json:"model,omitempty"`
+	PageName       string                 `protobuf:"bytes,10,opt,name=page_name,json=pageName,proto3" json:"page_name,omitempty"`
+	Orientation    int32                  `protobuf:"varint,11,opt,name=orientation,proto3" json:"orientation,omitempty"`
+	IccProfile     string                 `protobuf:"bytes,12,opt,name=icc_profile,json=iccProfile,proto3" json:"icc_profile,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TiffPageInfo) Reset() {
+	*x = TiffPageInfo{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TiffPageInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TiffPageInfo) ProtoMessage() {}
+
+func (x *TiffPageInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TiffPageInfo.ProtoReflect.Descriptor instead.
+func (*TiffPageInfo) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TiffPageInfo) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *TiffPageInfo) GetPhotometric() string {
+	if x != nil {
+		return x.Photometric
+	}
+	return ""
+}
+
+func (x *TiffPageInfo) GetXResolution() float64 {
+	if x != nil {
+		return x.XResolution
+	}
+	return 0
+}
+
+func (x *TiffPageInfo) GetYResolution() float64 {
+	if x != nil {
+		return x.YResolution
+	}
+	return 0
+}
+
+func (x *TiffPageInfo) GetResolutionUnit() string {
+	if x != nil {
+		return x.ResolutionUnit
+	}
+	return ""
+}
+
+func (x *TiffPageInfo) GetDateTime() string {
+	if x != nil {
+		return x.DateTime
+	}
+	return ""
+}
+
+func (x *TiffPageInfo) GetSoftware() string {
+	if x != nil {
+		return x.Software
+	}
+	return ""
+}
+
+func (x *TiffPageInfo) GetMake() string {
+	if x != nil {
+		return x.Make
+	}
+	return ""
+}
+
+func (x *TiffPageInfo) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *TiffPageInfo) GetPageName() string {
+	if x != nil {
+		return x.PageName
+	}
+	return ""
+}
+
+func (x *TiffPageInfo) GetOrientation() int32 {
+	if x != nil {
+		return x.Orientation
+	}
+	return 0
+}
+
+func (x *TiffPageInfo) GetIccProfile() string {
+	if x != nil {
+		return x.IccProfile
+	}
+	return ""
+}
+
+// GeoInfo contains the georeferencing of a GeoTIFF page
+type GeoInfo struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Epsg                int32                  `protobuf:"varint,1,opt,name=epsg,proto3" json:"epsg,omitempty"`                                                                  // EPSG code of the coordinate system
+	KeysJson            string                 `protobuf:"bytes,2,opt,name=keys_json,json=keysJson,proto3" json:"keys_json,omitempty"`                                           // GeoKey directory by key name, as a JSON object
+	Tiepoints           []*Tiepoint            `protobuf:"bytes,3,rep,name=tiepoints,proto3" json:"tiepoints,omitempty"`                                                         // Model tiepoints of the source image
+	PixelScale          []float64              `protobuf:"fixed64,4,rep,packed,name=pixel_scale,json=pixelScale,proto3" json:"pixel_scale,omitempty"`                            // Model pixel scale (X, Y, Z) of the source image
+	ModelTransformation []float64              `protobuf:"fixed64,5,rep,packed,name=model_transformation,json=modelTransformation,proto3" json:"model_transformation,omitempty"` // Model transformation matrix of the source image
+	GeoTransform        []float64              `protobuf:"fixed64,6,rep,packed,name=geo_transform,json=geoTransform,proto3" json:"geo_transform,omitempty"`                      // Affine transform of the output image in GDAL order
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GeoInfo) Reset() {
+	*x = GeoInfo{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GeoInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GeoInfo) ProtoMessage() {}
+
+func (x *GeoInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GeoInfo.ProtoReflect.Descriptor instead.
+func (*GeoInfo) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GeoInfo) GetEpsg() int32 {
+	if x != nil {
+		return x.Epsg
+	}
+	return 0
+}
+
+func (x *GeoInfo) GetKeysJson() string {
+	if x != nil {
+		return x.KeysJson
+	}
+	return ""
+}
+
+func (x *GeoInfo) GetTiepoints() []*Tiepoint {
+	if x != nil {
+		return x.Tiepoints
+	}
+	return nil
+}
+
+func (x *GeoInfo) GetPixelScale() []float64 {
+	if x != nil {
+		return x.PixelScale
+	}
+	return nil
+}
+
+func (x *GeoInfo) GetModelTransformation() []float64 {
+	if x != nil {
+		return x.ModelTransformation
+	}
+	return nil
+}
+
+func (x *GeoInfo) GetGeoTransform() []float64 {
+	if x != nil {
+		return x.GeoTransform
+	}
+	return nil
+}
+
+// Tiepoint is a model tiepoint (I, J, K, X, Y, Z)
+type Tiepoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []float64              `protobuf:"fixed64,1,rep,packed,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tiepoint) Reset() {
+	*x = Tiepoint{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tiepoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tiepoint) ProtoMessage() {}
+
+func (x *Tiepoint) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tiepoint.ProtoReflect.Descriptor instead.
+func (*Tiepoint) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Tiepoint) GetValues() []float64 {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// DocumentInfo contains document level metadata of a converted document
+type DocumentInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`         // Document format and version (e.g., "PDF 1.7")
+	Encryption    string                 `protobuf:"bytes,2,opt,name=encryption,proto3" json:"encryption,omitempty"` // Encryption method, empty if not encrypted
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Author        string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Subject       string                 `protobuf:"bytes,5,opt,name=subject,proto3" json:"subject,omitempty"`
+	Keywords      string                 `protobuf:"bytes,6,opt,name=keywords,proto3" json:"keywords,omitempty"`
+	Creator       string                 `protobuf:"bytes,7,opt,name=creator,proto3" json:"creator,omitempty"`
+	Producer      string                 `protobuf:"bytes,8,opt,name=producer,proto3" json:"producer,omitempty"`
+	CreationDate  string                 `protobuf:"bytes,9,opt,name=creation_date,json=creationDate,proto3" json:"creation_date,omitempty"` // RFC 3339 when parseable
+	ModDate       string                 `protobuf:"bytes,10,opt,name=mod_date,json=modDate,proto3" json:"mod_date,omitempty"`               // RFC 3339 when parseable
+	Pages         int32                  `protobuf:"varint,11,opt,name=pages,proto3" json:"pages,omitempty"`                                 // Total number of pages
+	Reflowable    bool                   `protobuf:"varint,12,opt,name=reflowable,proto3" json:"reflowable,omitempty"`                       // Pages were laid out by MuPDF (EPUB, FB2, MOBI)
+	PageLabels    []string               `protobuf:"bytes,13,rep,name=page_labels,json=pageLabels,proto3" json:"page_labels,omitempty"`      // Label of each page
+	PageSizes     []*PageSize            `protobuf:"bytes,14,rep,name=page_sizes,json=pageSizes,proto3" json:"page_sizes,omitempty"`         // Displayed size of each page
+	Outline       []*OutlineEntry        `protobuf:"bytes,15,rep,name=outline,proto3" json:"outline,omitempty"`                              // Table of contents
+	FormFields    []*FormField           `protobuf:"bytes,16,rep,name=form_fields,json=formFields,proto3" json:"form_fields,omitempty"`      // Form fields if annotation extraction was requested
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DocumentInfo) Reset() {
+	*x = DocumentInfo{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DocumentInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DocumentInfo) ProtoMessage() {}
+
+func (x *DocumentInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DocumentInfo.ProtoReflect.Descriptor instead.
+func (*DocumentInfo) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DocumentInfo) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *DocumentInfo) GetEncryption() string {
+	if x != nil {
+		return x.Encryption
+	}
+	return ""
+}
+
+func (x *DocumentInfo) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *DocumentInfo) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *DocumentInfo) GetSubject() string {
+	if x != nil {
+		return x.Subject
+	}
+	return ""
+}
+
+func (x *DocumentInfo) GetKeywords() string {
+	if x != nil {
+		return x.Keywords
+	}
+	return ""
+}
+
+func (x *DocumentInfo) GetCreator() string {
+	if x != nil {
+		return x.Creator
+	}
+	return ""
+}
+
+func (x *DocumentInfo) GetProducer() string {
+	if x != nil {
+		return x.Producer
+	}
+	return ""
+}
+
+func (x *DocumentInfo) GetCreationDate() string {
+	if x != nil {
+		return x.CreationDate
+	}
+	return ""
+}
+
+func (x *DocumentInfo) GetModDate() string {
+	if x != nil {
+		return x.ModDate
+	}
+	return ""
+}
+
+func (x *DocumentInfo) GetPages() int32 {
+	if x != nil {
+		return x.Pages
+	}
+	return 0
+}
+
+func (x *DocumentInfo) GetReflowable() bool {
+	if x != nil {
+		return x.Reflowable
+	}
+	return false
+}
+
+func (x *DocumentInfo) GetPageLabels() []string {
+	if x != nil {
+		return x.PageLabels
+	}
+	return nil
+}
+
+func (x *DocumentInfo) GetPageSizes() []*PageSize {
+	if x != nil {
+		return x.PageSizes
+	}
+	return nil
+}
+
+func (x *DocumentInfo) GetOutline() []*OutlineEntry {
+	if x != nil {
+		return x.Outline
+	}
+	return nil
+}
+
+func (x *DocumentInfo) GetFormFields() []*FormField {
+	if x != nil {
+		return x.FormFields
+	}
+	return nil
+}
+
+// PageSize contains the displayed size of a page in PDF points (1/72 inch): its crop
+// box, or else its media box, after the page's rotation
+type PageSize struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Page          int32                  `protobuf:"varint,1,opt,name=page,proto3" json:"page,omitempty"`
+	Width         float64                `protobuf:"fixed64,2,opt,name=width,proto3" json:"width,omitempty"`
+	Height        float64                `protobuf:"fixed64,3,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PageSize) Reset() {
+	*x = PageSize{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PageSize) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PageSize) ProtoMessage() {}
+
+func (x *PageSize) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PageSize.ProtoReflect.Descriptor instead.
+func (*PageSize) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PageSize) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *PageSize) GetWidth() float64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *PageSize) GetHeight() float64 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+// OutlineEntry is an entry of a document's table of contents
+type OutlineEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         int32                  `protobuf:"varint,1,opt,name=level,proto3" json:"level,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Page          int32                  `protobuf:"varint,3,opt,name=page,proto3" json:"page,omitempty"`
+	Uri           string                 `protobuf:"bytes,4,opt,name=uri,proto3" json:"uri,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutlineEntry) Reset() {
+	*x = OutlineEntry{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutlineEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutlineEntry) ProtoMessage() {}
+
+func (x *OutlineEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutlineEntry.ProtoReflect.Descriptor instead.
+func (*OutlineEntry) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *OutlineEntry) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *OutlineEntry) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *OutlineEntry) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *OutlineEntry) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+// FormField is a form field of a PDF document
+type FormField struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	Page          int32                  `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"` // Page of the field's first widget (1-based)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FormField) Reset() {
+	*x = FormField{}
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FormField) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FormField) ProtoMessage() {}
+
+func (x *FormField) ProtoReflect() protoreflect.Message {
+	mi := &file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FormField.ProtoReflect.Descriptor instead.
+func (*FormField) Descriptor() ([]byte, []int) {
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *FormField) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FormField) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *FormField) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *FormField) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+var File_tifpdf2png_v1_tifpdf2png_proto protoreflect.FileDescriptor
+
+const file_tifpdf2png_v1_tifpdf2png_proto_rawDesc = "" +
+	"\n" +
+	"\x1etifpdf2png/v1/tifpdf2png.proto\x12\rtifpdf2png.v1\"\x8a\x01\n" +
+	"\x0eConvertRequest\x12\x1a\n" +
+	"\bdocument\x18\x01 \x01(\fR\bdocument\x127\n" +
+	"\aoptions\x18\x02 \x01(\v2\x1d.tifpdf2png.v1.ConvertOptionsR\aoptions\x12#\n" +
+	"\rdocument_info\x18\x03 \x01(\bR\fdocumentInfo\"\xbd\x02\n" +
+	"\x0eConvertOptions\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x10\n" +
+	"\x03dpi\x18\x02 \x01(\x01R\x03dpi\x12\x14\n" +
+	"\x05pages\x18\x03 \x01(\tR\x05pages\x12\x17\n" +
+	"\ano_crop\x18\x04 \x01(\bR\x06noCrop\x12\x1f\n" +
+	"\vno_binarize\x18\x05 \x01(\bR\n" +
+	"noBinarize\x12\x1c\n" +
+	"\tthreshold\x18\x06 \x01(\rR\tthreshold\x12!\n" +
+	"\fextract_text\x18\a \x01(\bR\vextractText\x12#\n" +
+	"\rextract_links\x18\b \x01(\bR\fextractLinks\x12/\n" +
+	"\x13extract_annotations\x18\t \x01(\bR\x12extractAnnotations\x12\x1a\n" +
+	"\bpassword\x18\n" +
+	" \x01(\tR\bpassword\"u\n" +
+	"\x0fConvertResponse\x12)\n" +
+	"\x05pages\x18\x01 \x03(\v2\x13.tifpdf2png.v1.PageR\x05pages\x127\n" +
+	"\bdocument\x18\x02 \x01(\v2\x1b.tifpdf2png.v1.DocumentInfoR\bdocument\"\x87\x01\n" +
+	"\x15ConvertStreamResponse\x12)\n" +
+	"\x04page\x18\x01 \x01(\v2\x13.tifpdf2png.v1.PageH\x00R\x04page\x129\n" +
+	"\bdocument\x18\x02 \x01(\v2\x1b.tifpdf2png.v1.DocumentInfoH\x00R\bdocumentB\b\n" +
+	"\x06result\"N\n" +
+	"\x04Page\x122\n" +
+	"\x06detail\x18\x01 \x01(\v2\x1a.tifpdf2png.v1.ImageDetailR\x06detail\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"\xc8\x05\n" +
+	"\vImageDetail\x12\x1f\n" +
+	"\vactual_type\x18\x01 \x01(\tR\n" +
+	"actualType\x12#\n" +
+	"\rsource_format\x18\x02 \x01(\tR\fsourceFormat\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05pages\x18\x04 \x01(\x05R\x05pages\x12\x1b\n" +
+	"\tsub_image\x18\x05 \x01(\x05R\bsubImage\x12\x10\n" +
+	"\x03url\x18\x06 \x01(\tR\x03url\x12\x14\n" +
+	"\x05width\x18\a \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\b \x01(\x05R\x06height\x12\x16\n" +
+	"\x06format\x18\t \x01(\tR\x06format\x12\x18\n" +
+	"\aquality\x18\n" +
+	" \x01(\x01R\aquality\x12:\n" +
+	"\vcrop_detail\x18\v \x01(\v2\x19.tifpdf2png.v1.CropDetailR\n" +
+	"cropDetail\x12+\n" +
+	"\x04text\x18\f \x01(\v2\x17.tifpdf2png.v1.PageTextR\x04text\x12;\n" +
+	"\vannotations\x18\r \x03(\v2\x19.tifpdf2png.v1.AnnotationR\vannotations\x12\x14\n" +
+	"\x05links\x18\x0e \x03(\tR\x05links\x12\x1c\n" +
+	"\textracted\x18\x0f \x01(\bR\textracted\x12/\n" +
+	"\x04tiff\x18\x10 \x01(\v2\x1b.tifpdf2png.v1.TiffPageInfoR\x04tiff\x128\n" +
+	"\n" +
+	"source_dpi\x18\x11 \x01(\v2\x19.tifpdf2png.v1.ResolutionR\tsourceDpi\x12+\n" +
+	"\x03dpi\x18\x12 \x01(\v2\x19.tifpdf2png.v1.ResolutionR\x03dpi\x12(\n" +
+	"\x03geo\x18\x13 \x01(\v2\x16.tifpdf2png.v1.GeoInfoR\x03geo\x12\x1e\n" +
+	"\n" +
+	"transforms\x18\x14 \x03(\tR\n" +
+	"transforms\"\xde\x01\n" +
+	"\n" +
+	"CropDetail\x12\x19\n" +
+	"\boffset_x\x18\x01 \x01(\x05R\aoffsetX\x12\x19\n" +
+	"\boffset_y\x18\x02 \x01(\x05R\aoffsetY\x12%\n" +
+	"\x0eoriginal_width\x18\x03 \x01(\x05R\roriginalWidth\x12'\n" +
+	"\x0foriginal_height\x18\x04 \x01(\x05R\x0eoriginalHeight\x12#\n" +
+	"\rcropped_width\x18\x05 \x01(\x05R\fcroppedWidth\x12%\n" +
+	"\x0ecropped_height\x18\x06 \x01(\x05R\rcroppedHeight\"(\n" +
+	"\n" +
+	"Resolution\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x01R\x01y\"f\n" +
+	"\bPageText\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent\x12\x12\n" +
+	"\x04html\x18\x02 \x01(\tR\x04html\x12,\n" +
+	"\x05words\x18\x03 \x03(\v2\x16.tifpdf2png.v1.WordBoxR\x05words\"\x85\x01\n" +
+	"\aWordBox\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\x12\f\n" +
+	"\x01x\x18\x02 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x03 \x01(\x05R\x01y\x12\x14\n" +
+	"\x05width\x18\x04 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x05 \x01(\x05R\x06height\x12\x1c\n" +
+	"\testimated\x18\x06 \x01(\bR\testimated\"\x99\x02\n" +
+	"\n" +
+	"Annotation\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\f\n" +
+	"\x01x\x18\x02 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x03 \x01(\x05R\x01y\x12\x14\n" +
+	"\x05width\x18\x04 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x05 \x01(\x05R\x06height\x12\x1a\n" +
+	"\bcontents\x18\x06 \x01(\tR\bcontents\x12\x1d\n" +
+	"\n" +
+	"field_name\x18\a \x01(\tR\tfieldName\x12\x1d\n" +
+	"\n" +
+	"field_type\x18\b \x01(\tR\tfieldType\x12\x1f\n" +
+	"\vfield_value\x18\t \x01(\tR\n" +
+	"fieldValue\x12\x16\n" +
+	"\x06hidden\x18\n" +
+	" \x01(\bR\x06hidden\x12\x1a\n" +
+	"\brendered\x18\v \x01(\bR\brendered\"\x84\x03\n" +
+	"\fTiffPageInfo\x12 \n" +
+	"\vcompression\x18\x01 \x01(\tR\vcompression\x12 \n" +
+	"\vphotometric\x18\x02 \x01(\tR\vphotometric\x12!\n" +
+	"\fx_resolution\x18\x03 \x01(\x01R\vxResolution\x12!\n" +
+	"\fy_resolution\x18\x04 \x01(\x01R\vyResolution\x12'\n" +
+	"\x0fresolution_unit\x18\x05 \x01(\tR\x0eresolutionUnit\x12\x1b\n" +
+	"\tdate_time\x18\x06 \x01(\tR\bdateTime\x12\x1a\n" +
+	"\bsoftware\x18\a \x01(\tR\bsoftware\x12\x12\n" +
+	"\x04make\x18\b \x01(\tR\x04make\x12\x14\n" +
+	"\x05model\x18\t \x01(\tR\x05model\x12\x1b\n" +
+	"\tpage_name\x18\n" +
+	" \x01(\tR\bpageName\x12 \n" +
+	"\vorientation\x18\v \x01(\x05R\vorientation\x12\x1f\n" +
+	"\vicc_profile\x18\f \x01(\tR\n" +
+	"iccProfile\"\xea\x01\n" +
+	"\aGeoInfo\x12\x12\n" +
+	"\x04epsg\x18\x01 \x01(\x05R\x04epsg\x12\x1b\n" +
+	"\tkeys_json\x18\x02 \x01(\tR\bkeysJson\x125\n" +
+	"\ttiepoints\x18\x03 \x03(\v2\x17.tifpdf2png.v1.TiepointR\ttiepoints\x12\x1f\n" +
+	"\vpixel_scale\x18\x04 \x03(\x01R\n" +
+	"pixelScale\x121\n" +
+	"\x14model_transformation\x18\x05 \x03(\x01R\x13modelTransformation\x12#\n" +
+	"\rgeo_transform\x18\x06 \x03(\x01R\fgeoTransform\"\"\n" +
+	"\bTiepoint\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\x01R\x06values\"\xa1\x04\n" +
+	"\fDocumentInfo\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x1e\n" +
+	"\n" +
+	"encryption\x18\x02 \x01(\tR\n" +
+	"encryption\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12\x18\n" +
+	"\asubject\x18\x05 \x01(\tR\asubject\x12\x1a\n" +
+	"\bkeywords\x18\x06 \x01(\tR\bkeywords\x12\x18\n" +
+	"\acreator\x18\a \x01(\tR\acreator\x12\x1a\n" +
+	"\bproducer\x18\b \x01(\tR\bproducer\x12#\n" +
+	"\rcreation_date\x18\t \x01(\tR\fcreationDate\x12\x19\n" +
+	"\bmod_date\x18\n" +
+	" \x01(\tR\amodDate\x12\x14\n" +
+	"\x05pages\x18\v \x01(\x05R\x05pages\x12\x1e\n" +
+	"\n" +
+	"reflowable\x18\f \x01(\bR\n" +
+	"reflowable\x12\x1f\n" +
+	"\vpage_labels\x18\r \x03(\tR\n" +
+	"pageLabels\x126\n" +
+	"\n" +
+	"page_sizes\x18\x0e \x03(\v2\x17.tifpdf2png.v1.PageSizeR\tpageSizes\x125\n" +
+	"\aoutline\x18\x0f \x03(\v2\x1b.tifpdf2png.v1.OutlineEntryR\aoutline\x129\n" +
+	"\vform_fields\x18\x10 \x03(\v2\x18.tifpdf2png.v1.FormFieldR\n" +
+	"formFields\"L\n" +
+	"\bPageSize\x12\x12\n" +
+	"\x04page\x18\x01 \x01(\x05R\x04page\x12\x14\n" +
+	"\x05width\x18\x02 \x01(\x01R\x05width\x12\x16\n" +
+	"\x06height\x18\x03 \x01(\x01R\x06height\"`\n" +
+	"\fOutlineEntry\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\x05R\x05level\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x12\n" +
+	"\x04page\x18\x03 \x01(\x05R\x04page\x12\x10\n" +
+	"\x03uri\x18\x04 \x01(\tR\x03uri\"]\n" +
+	"\tFormField\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\x12\x12\n" +
+	"\x04page\x18\x04 \x01(\x05R\x04page2\xb4\x01\n" +
+	"\x10ConverterService\x12H\n" +
+	"\aConvert\x12\x1d.tifpdf2png.v1.ConvertRequest\x1a\x1e.tifpdf2png.v1.ConvertResponse\x12V\n" +
+	"\rConvertStream\x12\x1d.tifpdf2png.v1.ConvertRequest\x1a$.tifpdf2png.v1.ConvertStreamResponse0\x01BCZAgithub.com/dhushon/go-tifpdf2png/proto/tifpdf2png/v1;tifpdf2pngv1b\x06proto3"
+
+var (
+	file_tifpdf2png_v1_tifpdf2png_proto_rawDescOnce sync.Once
+	file_tifpdf2png_v1_tifpdf2png_proto_rawDescData []byte
+)
+
+func file_tifpdf2png_v1_tifpdf2png_proto_rawDescGZIP() []byte {
+	file_tifpdf2png_v1_tifpdf2png_proto_rawDescOnce.Do(func() {
+		file_tifpdf2png_v1_tifpdf2png_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tifpdf2png_v1_tifpdf2png_proto_rawDesc), len(file_tifpdf2png_v1_tifpdf2png_proto_rawDesc)))
+	})
+	return file_tifpdf2png_v1_tifpdf2png_proto_rawDescData
+}
+
+var file_tifpdf2png_v1_tifpdf2png_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_tifpdf2png_v1_tifpdf2png_proto_goTypes = []any{
+	(*ConvertRequest)(nil),        // 0: tifpdf2png.v1.ConvertRequest
+	(*ConvertOptions)(nil),        // 1: tifpdf2png.v1.ConvertOptions
+	(*ConvertResponse)(nil),       // 2: tifpdf2png.v1.ConvertResponse
+	(*ConvertStreamResponse)(nil), // 3: tifpdf2png.v1.ConvertStreamResponse
+	(*Page)(nil),                  // 4: tifpdf2png.v1.Page
+	(*ImageDetail)(nil),           // 5: tifpdf2png.v1.ImageDetail
+	(*CropDetail)(nil),            // 6: tifpdf2png.v1.CropDetail
+	(*Resolution)(nil),            // 7: tifpdf2png.v1.Resolution
+	(*PageText)(nil),              // 8: tifpdf2png.v1.PageText
+	(*WordBox)(nil),               // 9: tifpdf2png.v1.WordBox
+	(*Annotation)(nil),            // 10: tifpdf2png.v1.Annotation
+	(*TiffPageInfo)(nil),          // 11: tifpdf2png.v1.TiffPageInfo
+	(*GeoInfo)(nil),               // 12: tifpdf2png.v1.GeoInfo
+	(*Tiepoint)(nil),              // 13: tifpdf2png.v1.Tiepoint
+	(*DocumentInfo)(nil),          // 14: tifpdf2png.v1.DocumentInfo
+	(*PageSize)(nil),              // 15: tifpdf2png.v1.PageSize
+	(*OutlineEntry)(nil),          // 16: tifpdf2png.v1.OutlineEntry
+	(*FormField)(nil),             // 17: tifpdf2png.v1.FormField
+}
+var file_tifpdf2png_v1_tifpdf2png_proto_depIdxs = []int32{
+	1,  // 0: tifpdf2png.v1.ConvertRequest.options:type_name -> tifpdf2png.v1.ConvertOptions
+	4,  // 1: tifpdf2png.v1.ConvertResponse.pages:type_name -> tifpdf2png.v1.Page
+	14, // 2: tifpdf2png.v1.ConvertResponse.document:type_name -> tifpdf2png.v1.DocumentInfo
+	4,  // 3: tifpdf2png.v1.ConvertStreamResponse.page:type_name -> tifpdf2png.v1.Page
+	14, // 4: tifpdf2png.v1.ConvertStreamResponse.document:type_name -> tifpdf2png.v1.DocumentInfo
+	5,  // 5: tifpdf2png.v1.Page.detail:type_name -> tifpdf2png.v1.ImageDetail
+	6,  // 6: tifpdf2png.v1.ImageDetail.crop_detail:type_name -> tifpdf2png.v1.CropDetail
+	8,  // 7: tifpdf2png.v1.ImageDetail.text:type_name -> tifpdf2png.v1.PageText
+	10, // 8: tifpdf2png.v1.ImageDetail.annotations:type_name -> tifpdf2png.v1.Annotation
+	11, // 9: tifpdf2png.v1.ImageDetail.tiff:type_name -> tifpdf2png.v1.TiffPageInfo
+	7,  // 10: tifpdf2png.v1.ImageDetail.source_dpi:type_name -> tifpdf2png.v1.Resolution
+	7,  // 11: tifpdf2png.v1.ImageDetail.dpi:type_name -> tifpdf2png.v1.Resolution
+	12, // 12: tifpdf2png.v1.ImageDetail.geo:type_name -> tifpdf2png.v1.GeoInfo
+	9,  // 13: tifpdf2png.v1.PageText.words:type_name -> tifpdf2png.v1.WordBox
+	13, // 14: tifpdf2png.v1.GeoInfo.tiepoints:type_name -> tifpdf2png.v1.Tiepoint
+	15, // 15: tifpdf2png.v1.DocumentInfo.page_sizes:type_name -> tifpdf2png.v1.PageSize
+	16, // 16: tifpdf2png.v1.DocumentInfo.outline:type_name -> tifpdf2png.v1.OutlineEntry
+	17, // 17: tifpdf2png.v1.DocumentInfo.form_fields:type_name -> tifpdf2png.v1.FormField
+	0,  // 18: tifpdf2png.v1.ConverterService.Convert:input_type -> tifpdf2png.v1.ConvertRequest
+	0,  // 19: tifpdf2png.v1.ConverterService.ConvertStream:input_type -> tifpdf2png.v1.ConvertRequest
+	2,  // 20: tifpdf2png.v1.ConverterService.Convert:output_type -> tifpdf2png.v1.ConvertResponse
+	3,  // 21: tifpdf2png.v1.ConverterService.ConvertStream:output_type -> tifpdf2png.v1.ConvertStreamResponse
+	20, // [20:22] is the sub-list for method output_type
+	18, // [18:20] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_tifpdf2png_v1_tifpdf2png_proto_init() }
+func file_tifpdf2png_v1_tifpdf2png_proto_init() {
+	if File_tifpdf2png_v1_tifpdf2png_proto != nil {
+		return
+	}
+	file_tifpdf2png_v1_tifpdf2png_proto_msgTypes[3].OneofWrappers = []any{
+		(*ConvertStreamResponse_Page)(nil),
+		(*ConvertStreamResponse_Document)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tifpdf2png_v1_tifpdf2png_proto_rawDesc), len(file_tifpdf2png_v1_tifpdf2png_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tifpdf2png_v1_tifpdf2png_proto_goTypes,
+		DependencyIndexes: file_tifpdf2png_v1_tifpdf2png_proto_depIdxs,
+		MessageInfos:      file_tifpdf2png_v1_tifpdf2png_proto_msgTypes,
+	}.Build()
+	File_tifpdf2png_v1_tifpdf2png_proto = out.File
+	file_tifpdf2png_v1_tifpdf2png_proto_goTypes = nil
+	file_tifpdf2png_v1_tifpdf2png_proto_depIdxs = nil
+}
@@ -0,0 +1,175 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: tifpdf2png/v1/tifpdf2png.proto
+
+package tifpdf2pngv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ConverterService_Convert_FullMethodName       = "/tifpdf2png.v1.ConverterService/Convert"
+	ConverterService_ConvertStream_FullMethodName = "/tifpdf2png.v1.ConverterService/ConvertStream"
+)
+
+// ConverterServiceClient is the client API for ConverterService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ConverterService converts TIFF, PDF, XPS, e-book and raster image documents to page images
+type ConverterServiceClient interface {
+	// Convert converts a document and returns all of its pages in one message, which must fit
+	// the message size limits; use ConvertStream for large documents
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
+	// ConvertStream converts a document and streams each page as soon as it is saved,
+	// followed by the document info when requested
+	ConvertStream(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ConvertStreamResponse], error)
+}
+
+type converterServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewConverterServiceClient(cc grpc.ClientConnInterface) ConverterServiceClient {
+	return &converterServiceClient{cc}
+}
+
+func (c *converterServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConvertResponse)
+	err := c.cc.Invoke(ctx, ConverterService_Convert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *converterServiceClient) ConvertStream(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ConvertStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ConverterService_ServiceDesc.Streams[0], ConverterService_ConvertStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ConvertRequest, ConvertStreamResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ConverterService_ConvertStreamClient = grpc.ServerStreamingClient[ConvertStreamResponse]
+
+// ConverterServiceServer is the server API for ConverterService service.
+// All implementations must embed UnimplementedConverterServiceServer
+// for forward compatibility.
+//
+// ConverterService converts TIFF, PDF, XPS, e-book and raster image documents to page images
+type ConverterServiceServer interface {
+	// Convert converts a document and returns all of its pages in one message, which must fit
+	// the message size limits; use ConvertStream for large documents
+	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
+	// ConvertStream converts a document and streams each page as soon as it is saved,
+	// followed by the document info when requested
+	ConvertStream(*ConvertRequest, grpc.ServerStreamingServer[ConvertStreamResponse]) error
+	mustEmbedUnimplementedConverterServiceServer()
+}
+
+// UnimplementedConverterServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedConverterServiceServer struct{}
+
+func (UnimplementedConverterServiceServer) Convert(context.Context, *ConvertRequest) (*ConvertResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedConverterServiceServer) ConvertStream(*ConvertRequest, grpc.ServerStreamingServer[ConvertStreamResponse]) error {
+	return status.Error(codes.Unimplemented, "method ConvertStream not implemented")
+}
+func (UnimplementedConverterServiceServer) mustEmbedUnimplementedConverterServiceServer() {}
+func (UnimplementedConverterServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeConverterServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ConverterServiceServer will
+// result in compilation errors.
+type UnsafeConverterServiceServer interface {
+	mustEmbedUnimplementedConverterServiceServer()
+}
+
+func RegisterConverterServiceServer(s grpc.ServiceRegistrar, srv ConverterServiceServer) {
+	// If the following call panics, it indicates UnimplementedConverterServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ConverterService_ServiceDesc, srv)
+}
+
+func _ConverterService_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ConverterServiceServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ConverterService_Convert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ConverterServiceServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ConverterService_ConvertStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ConvertRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ConverterServiceServer).ConvertStream(m, &grpc.GenericServerStream[ConvertRequest, ConvertStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ConverterService_ConvertStreamServer = grpc.ServerStreamingServer[ConvertStreamResponse]
+
+// ConverterService_ServiceDesc is the grpc.ServiceDesc for ConverterService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ConverterService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tifpdf2png.v1.ConverterService",
+	HandlerType: (*ConverterServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Convert",
+			Handler:    _ConverterService_Convert_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ConvertStream",
+			Handler:       _ConverterService_ConvertStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tifpdf2png/v1/tifpdf2png.proto",
+}
@@ -0,0 +1,247 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	_ "image/gif" // Decoders registered for image.Decode
+	_ "image/jpeg"
+	_ "image/png"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+)
+
+// rasterImageFormats maps the extensions of supported raster image inputs to their format
+var rasterImageFormats = map[string]string{
+	".jpg":   "jpeg",
+	".jpeg":  "jpeg",
+	".png":   "png",
+	".bmp":   "bmp",
+	".gif":   "gif",
+	".webp":  "webp",
+	".jb2":   "jbig2",
+	".jbig2": "jbig2",
+}
+
+// exifOrientationTag is the EXIF tag holding the orientation of a photo
+const exifOrientationTag = 0x0112
+
+// IsRasterImage reports whether a file has the extension of a raster image supported
+// by ConvertImageToPngWithOptions
+func IsRasterImage(filename string) bool {
+	_, ok := rasterImageFormats[strings.ToLower(filepath.Ext(filename))]
+	return ok
+}
+
+// ConvertImageToPngWithImageDetails converts a JPEG, PNG, BMP, GIF, WebP or JBIG2 image
+// to PNG and returns ImageDetail slice
+func ConvertImageToPngWithImageDetails(imageFilename string, destpath string, prefix string) ([]*ImageDetail, error) {
+	return ConvertImageToPngWithOptions(imageFilename, destpath, prefix, nil)
+}
+
+// ConvertImageToPngWithOptions converts a raster image to PNG through the same crop and
+// background normalization as TIFF pages and returns ImageDetail slice. JPEG photos are
+// turned upright according to their EXIF orientation. Animated GIFs are converted from
+// their first frame, and every page of a JBIG2 file is converted. Options that only
+// apply to PDFs or TIFFs are ignored.
+func ConvertImageToPngWithOptions(imageFilename string, destpath string, prefix string, opts *Options) ([]*ImageDetail, error) {
+	return convertImage(fileSource(imageFilename), destpath, prefix, opts)
+}
+
+// convertImage converts a raster image, or every page of a JBIG2 file
+func convertImage(src *source, destpath string, prefix string, opts *Options) ([]*ImageDetail, error) {
+	started := time.Now()
+	opts = opts.orDefault()
+	if err := opts.Validate(); err != nil {
+		slog.Error("ConvertImageToPngWithOptions: invalid options", "error", err)
+		return nil, err
+	}
+
+	format, ok := rasterImageFormats[src.ext]
+	if !ok {
+		err := fmt.Errorf("unsupported image type %q", src.ext)
+		slog.Error("ConvertImageToPngWithOptions: invalid input", "error", err)
+		return nil, err
+	}
+
+	var images []image.Image
+	var transforms []string
+	var err error
+	if format == "jbig2" {
+		images, err = decodeJBIG2(src, opts.renderDPI())
+	} else {
+		var img image.Image
+		img, transforms, err = decodeRasterImage(src)
+		images = []image.Image{img}
+	}
+	if err != nil {
+		slog.Error("ConvertImageToPngWithOptions: decode image", "filename", src.path, "error", err)
+		return nil, err
+	}
+
+	if prefix == "" {
+		prefix = time.Now().Format("20060102-150405-")
+	}
+	sink := opts.sinkFor(destpath)
+
+	var imageDetails []*ImageDetail
+	for i, img := range images {
+		if !opts.Pages.includes(i + 1) {
+			continue
+		}
+		outputFilename := prefix + strconv.Itoa(i) + ".png"
+
+		whiteBackgroundFrame, cropInfo := normalizePage(img, opts)
+		url, err := saveImageAsPng(sink, whiteBackgroundFrame, outputFilename)
+		if err != nil {
+			return nil, err
+		}
+
+		cropDetail, imageWidth, imageHeight := cropDetailFor(cropInfo)
+		imageDetail := &ImageDetail{
+			ActualType:   "png",
+			SourceFormat: format,
+			Page:         i + 1,
+			Pages:        len(images),
+			URL:          url,
+			Width:        imageWidth,
+			Height:       imageHeight,
+			Format:       "png",
+			Quality:      95.0,
+			CropDetail:   cropDetail,
+			Transforms:   transforms,
+		}
+		if err := opts.pageSaved(imageDetail); err != nil {
+			return nil, err
+		}
+		imageDetails = append(imageDetails, imageDetail)
+	}
+
+	if opts.WriteManifest {
+		if err := writeManifest(src, destpath, opts, len(images), started, imageDetails); err != nil {
+			slog.Error("ConvertImageToPngWithOptions: write manifest", "error", err)
+			return nil, err
+		}
+	}
+
+	return imageDetails, nil
+}
+
+// decodeRasterImage decodes an image with the standard and x/image decoders, applying
+// the EXIF orientation of JPEG photos
+func decodeRasterImage(src *source) (image.Image, []string, error) {
+	data, err := src.read()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if format != "jpeg" {
+		return img, nil, nil
+	}
+	img, transform := applyOrientation(img, jpegOrientation(data))
+	if transform == "" {
+		return img, nil, nil
+	}
+	return img, []string{transform}, nil
+}
+
+// decodeJBIG2 decodes every page of a JBIG2 file with MuPDF, which has the only JBIG2
+// decoder available here. Pages are extracted at their native resolution when possible
+// and otherwise rendered at dpi.
+func decodeJBIG2(src *source, dpi float64) ([]image.Image, error) {
+	doc, err := src.openFitz()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := doc.Close(); err != nil {
+			slog.Warn("Failed to close JBIG2 document", "error", err)
+		}
+	}()
+
+	var images []image.Image
+	for pageNum := 0; pageNum < doc.NumPage(); pageNum++ {
+		if embedded := fullPageImage(doc, pageNum); embedded != nil {
+			images = append(images, embedded.img)
+			continue
+		}
+		img, err := doc.ImageDPI(pageNum, dpi)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	if len(images) == 0 {
+		return nil, fmt.Errorf("no pages found in JBIG2 file")
+	}
+	return images, nil
+}
+
+// jpegOrientation returns the EXIF orientation of a JPEG file, or 0 if it has none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xff || data[1] != 0xd8 {
+		return 0
+	}
+
+	// Walk the marker segments up to the start of the scan looking for the EXIF APP1 segment
+	for pos := 2; pos+4 <= len(data); {
+		if data[pos] != 0xff {
+			return 0
+		}
+		marker := data[pos+1]
+		size := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if marker == 0xda || size < 2 || pos+2+size > len(data) {
+			return 0
+		}
+		segment := data[pos+4 : pos+2+size]
+		if marker == 0xe1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + size
+	}
+	return 0
+}
+
+// exifOrientation reads the orientation tag from the first IFD of a TIFF-structured EXIF block
+func exifOrientation(exif []byte) int {
+	if len(exif) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(exif[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	ifd := int(order.Uint32(exif[4:]))
+	if ifd < 8 || ifd+2 > len(exif) {
+		return 0
+	}
+	count := int(order.Uint16(exif[ifd:]))
+	for i := 0; i < count; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(exif) {
+			return 0
+		}
+		if order.Uint16(exif[entry:]) == exifOrientationTag {
+			return int(order.Uint16(exif[entry+8:]))
+		}
+	}
+	return 0
+}
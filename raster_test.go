@@ -0,0 +1,118 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/bmp"
+)
+
+// testWebP is a 1x1 lossy WebP image
+const testWebP = "UklGRiIAAABXRUJQVlA4IBYAAAAwAQCdASoBAAEADsD+JaQAA3AAAAAA"
+
+// withExifOrientation inserts an EXIF APP1 segment holding an orientation after the SOI marker of a JPEG
+func withExifOrientation(jpegData []byte, orientation uint16) []byte {
+	exif := []byte("Exif\x00\x00II*\x00\x08\x00\x00\x00\x01\x00")
+	exif = binary.LittleEndian.AppendUint16(exif, exifOrientationTag)
+	exif = binary.LittleEndian.AppendUint16(exif, 3)
+	exif = binary.LittleEndian.AppendUint32(exif, 1)
+	exif = binary.LittleEndian.AppendUint16(exif, orientation)
+	exif = append(exif, 0, 0, 0, 0, 0, 0)
+
+	segment := []byte{0xff, 0xe1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(len(exif)+2))
+	segment = append(segment, exif...)
+
+	out := append([]byte{}, jpegData[:2]...)
+	out = append(out, segment...)
+	return append(out, jpegData[2:]...)
+}
+
+func TestConvertRasterImages(t *testing.T) {
+	dir := t.TempDir()
+
+	// A light 8x4 image with a dark pixel in the top left corner
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 8; x++ {
+			img.SetNRGBA(x, y, color.NRGBA{R: 230, G: 230, B: 230, A: 255})
+		}
+	}
+	img.SetNRGBA(0, 0, color.NRGBA{A: 255})
+
+	var pngData, jpegData, gifData, bmpData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	if err := jpeg.Encode(&jpegData, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("Failed to encode JPEG: %v", err)
+	}
+	if err := gif.Encode(&gifData, img, nil); err != nil {
+		t.Fatalf("Failed to encode GIF: %v", err)
+	}
+	if err := bmp.Encode(&bmpData, img); err != nil {
+		t.Fatalf("Failed to encode BMP: %v", err)
+	}
+	webpData, _ := base64.StdEncoding.DecodeString(testWebP)
+
+	for _, tc := range []struct {
+		file          string
+		data          []byte
+		format        string
+		width, height int
+		transforms    int
+	}{
+		{"scan.png", pngData.Bytes(), "png", 8, 4, 0},
+		{"photo.jpg", jpegData.Bytes(), "jpeg", 8, 4, 0},
+		{"rotated.jpeg", withExifOrientation(jpegData.Bytes(), 6), "jpeg", 4, 8, 1},
+		{"scan.gif", gifData.Bytes(), "gif", 8, 4, 0},
+		{"scan.bmp", bmpData.Bytes(), "bmp", 8, 4, 0},
+		{"pixel.webp", webpData, "webp", 1, 1, 0},
+	} {
+		path := filepath.Join(dir, tc.file)
+		if err := os.WriteFile(path, tc.data, 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", tc.file, err)
+		}
+		if !IsRasterImage(path) {
+			t.Errorf("%s: expected raster image", tc.file)
+		}
+
+		details, err := ConvertImageToPngWithOptions(path, dir, tc.file+"-", nil)
+		if err != nil {
+			t.Fatalf("%s: ConvertImageToPngWithOptions failed: %v", tc.file, err)
+		}
+		if len(details) != 1 {
+			t.Fatalf("%s: expected 1 page, got %d", tc.file, len(details))
+		}
+
+		detail := details[0]
+		if detail.SourceFormat != tc.format || detail.Format != "png" {
+			t.Errorf("%s: expected %s converted to png, got %s to %s", tc.file, tc.format, detail.SourceFormat, detail.Format)
+		}
+		if detail.Width != tc.width || detail.Height != tc.height {
+			t.Errorf("%s: expected %dx%d, got %dx%d", tc.file, tc.width, tc.height, detail.Width, detail.Height)
+		}
+		if len(detail.Transforms) != tc.transforms {
+			t.Errorf("%s: expected %d transforms, got %v", tc.file, tc.transforms, detail.Transforms)
+		}
+		if _, err := os.Stat(detail.URL); err != nil {
+			t.Errorf("%s: output not written: %v", tc.file, err)
+		}
+	}
+
+	if IsRasterImage("document.pdf") {
+		t.Error("Expected PDF not to be a raster image")
+	}
+	if _, err := ConvertImageToPngWithOptions(filepath.Join(dir, "scan.tga"), dir, "tga-", nil); err == nil {
+		t.Error("Expected error for unsupported image type")
+	}
+}
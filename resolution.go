@@ -0,0 +1,60 @@
+package tifpdf2png
+
+import (
+	"image"
+	"log/slog"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+// centimetersPerInch converts TIFF resolutions given per centimeter to DPI
+const centimetersPerInch = 2.54
+
+// tiffDPI returns the resolution of a TIFF page in DPI, or nil if it is unknown
+func tiffDPI(info *TiffPageInfo) *Resolution {
+	if info == nil || info.XResolution <= 0 || info.YResolution <= 0 {
+		return nil
+	}
+	switch info.ResolutionUnit {
+	case "inch":
+		return &Resolution{X: info.XResolution, Y: info.YResolution}
+	case "cm":
+		return &Resolution{X: info.XResolution * centimetersPerInch, Y: info.YResolution * centimetersPerInch}
+	default:
+		return nil
+	}
+}
+
+// resampleToSquarePixels resamples an image from its source resolution to square pixels
+// at the target resolution selected by the options, returning the output resolution.
+// Images are left unchanged when no resampling is requested or the source is unknown.
+func resampleToSquarePixels(img image.Image, source *Resolution, opts *Options) (image.Image, *Resolution) {
+	if opts.TargetDPI <= 0 && !opts.SquarePixels {
+		return img, source
+	}
+	if source == nil {
+		slog.Warn("resampleToSquarePixels: source resolution unknown, keeping pixels as is")
+		return img, nil
+	}
+
+	target := opts.TargetDPI
+	if target <= 0 {
+		target = math.Max(source.X, source.Y)
+	}
+
+	bounds := img.Bounds()
+	width := max(1, int(math.Round(float64(bounds.Dx())*target/source.X)))
+	height := max(1, int(math.Round(float64(bounds.Dy())*target/source.Y)))
+	output := &Resolution{X: target, Y: target}
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return img, output
+	}
+
+	slog.Debug("Resampling page to square pixels",
+		"sourceDPI", source,
+		"targetDPI", target,
+		"width", width,
+		"height", height)
+	return imaging.Resize(img, width, height, imaging.Lanczos), output
+}
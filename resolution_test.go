@@ -0,0 +1,57 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertFaxTiffToSquarePixels(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "fax.tif")
+
+	// A fine-mode fax page has twice the horizontal resolution of its vertical resolution
+	writeRawTestTiff(t, tiffPath, []testTiffPage{{
+		width: 40, height: 20, pixels: bytes.Repeat([]byte{0x40}, 40*20),
+		tags: map[uint16]testTiffTag{
+			282: {tiffRational, [][2]uint32{{200, 1}}},
+			283: {tiffRational, [][2]uint32{{100, 1}}},
+		},
+	}})
+
+	for _, tc := range []struct {
+		name          string
+		opts          *Options
+		width, height int
+		dpi           Resolution
+	}{
+		{"unchanged", nil, 40, 20, Resolution{X: 200, Y: 100}},
+		{"square", &Options{SquarePixels: true}, 40, 40, Resolution{X: 200, Y: 200}},
+		{"target", &Options{TargetDPI: 50}, 10, 10, Resolution{X: 50, Y: 50}},
+	} {
+		details, err := ConvertTiffToPngWithOptions(tiffPath, dir, tc.name+"-", tc.opts)
+		if err != nil {
+			t.Fatalf("%s: ConvertTiffToPngWithOptions failed: %v", tc.name, err)
+		}
+
+		detail := details[0]
+		if detail.Width != tc.width || detail.Height != tc.height {
+			t.Errorf("%s: expected %dx%d, got %dx%d", tc.name, tc.width, tc.height, detail.Width, detail.Height)
+		}
+		if detail.SourceDPI == nil || *detail.SourceDPI != (Resolution{X: 200, Y: 100}) {
+			t.Errorf("%s: expected source DPI 200x100, got %+v", tc.name, detail.SourceDPI)
+		}
+		if detail.DPI == nil || *detail.DPI != tc.dpi {
+			t.Errorf("%s: expected output DPI %+v, got %+v", tc.name, tc.dpi, detail.DPI)
+		}
+	}
+}
+
+func TestTiffDPI(t *testing.T) {
+	if dpi := tiffDPI(&TiffPageInfo{XResolution: 100, YResolution: 50, ResolutionUnit: "cm"}); dpi == nil || *dpi != (Resolution{X: 254, Y: 127}) {
+		t.Errorf("Expected 254x127 DPI for resolution per centimeter, got %+v", dpi)
+	}
+	if dpi := tiffDPI(&TiffPageInfo{XResolution: 1, YResolution: 2, ResolutionUnit: "none"}); dpi != nil {
+		t.Errorf("Expected unknown DPI for aspect ratio only resolution, got %+v", dpi)
+	}
+}
@@ -0,0 +1,140 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config locates an S3-compatible object store, such as AWS S3 or MinIO
+type S3Config struct {
+	Endpoint string // Host and optional port, such as "s3.amazonaws.com" or "localhost:9000"
+	Region   string // Region of the buckets; empty looks it up for each bucket
+
+	AccessKey    string // Access key ID; empty with SecretKey makes anonymous requests
+	SecretKey    string // Secret access key
+	SessionToken string // Session token of temporary credentials
+
+	Insecure  bool // Use HTTP instead of HTTPS
+	PathStyle bool // Address buckets in the URL path instead of the host name
+}
+
+// client returns a client for the object store
+func (cfg *S3Config) client() (*minio.Client, error) {
+	if cfg == nil || cfg.Endpoint == "" {
+		return nil, errors.New("S3 endpoint is not configured")
+	}
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	return minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, cfg.SessionToken),
+		Secure:       !cfg.Insecure,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+}
+
+// ParseS3URL splits an s3://bucket/key URL into its bucket and key at the first slash
+// after the bucket. The key is taken as written, without URL decoding, so keys may hold
+// '#', '?' and '%'. The key is empty for s3://bucket and s3://bucket/.
+func ParseS3URL(s3URL string) (bucket string, key string, err error) {
+	rest, ok := strings.CutPrefix(s3URL, "s3://")
+	bucket, key, _ = strings.Cut(rest, "/")
+	if !ok || bucket == "" {
+		return "", "", fmt.Errorf("invalid S3 URL %q, expected s3://bucket/key", s3URL)
+	}
+	return bucket, key, nil
+}
+
+// OpenS3Object returns a reader of an object, to be converted with ConvertReaderWithOptions
+func OpenS3Object(cfg *S3Config, bucket string, key string) (io.ReadCloser, error) {
+	if key == "" {
+		return nil, fmt.Errorf("no object key in s3://%s", bucket)
+	}
+	client, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+	obj, err := client.GetObject(context.Background(), bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject only sends the request on the first read, so check that the object exists
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		return nil, fmt.Errorf("open s3://%s/%s: %w", bucket, key, err)
+	}
+	return obj, nil
+}
+
+// S3Sink writes output files as objects of a bucket, with their object URLs as URLs.
+// Each file is buffered until its writer is closed and then uploaded.
+type S3Sink struct {
+	client *minio.Client
+	bucket string
+	prefix string // Key prefix of the objects, ending in "/" unless empty
+}
+
+// NewS3Sink returns a sink writing output files to objects of a bucket, named after the
+// files below the key prefix
+func NewS3Sink(cfg *S3Config, bucket string, prefix string) (*S3Sink, error) {
+	client, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+	prefix = strings.Trim(prefix, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+	return &S3Sink{client: client, bucket: bucket, prefix: prefix}, nil
+}
+
+// Create implements PageSink
+func (s *S3Sink) Create(name string) (io.WriteCloser, string, error) {
+	key := s.prefix + strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, "\\", "/")), "/")
+	return &s3Object{sink: s, key: key}, s.objectURL(key), nil
+}
+
+// objectURL returns the path-style URL of an object
+func (s *S3Sink) objectURL(key string) string {
+	return s.client.EndpointURL().JoinPath(s.bucket, key).String()
+}
+
+// s3Object buffers an output file until it is uploaded on Close
+type s3Object struct {
+	sink   *S3Sink
+	key    string
+	buf    bytes.Buffer
+	closed bool
+}
+
+// Write implements io.Writer
+func (o *s3Object) Write(p []byte) (int, error) {
+	if o.closed {
+		return 0, errors.New("write to closed S3 object")
+	}
+	return o.buf.Write(p)
+}
+
+// Close implements io.Closer
+func (o *s3Object) Close() error {
+	if o.closed {
+		return nil
+	}
+	o.closed = true
+	opts := minio.PutObjectOptions{ContentType: mime.TypeByExtension(path.Ext(o.key))}
+	if _, err := o.sink.client.PutObject(context.Background(), o.sink.bucket, o.key, &o.buf, int64(o.buf.Len()), opts); err != nil {
+		return fmt.Errorf("upload s3://%s/%s: %w", o.sink.bucket, o.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package tifpdf2png
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testObjectStore is a minimal S3-compatible stand-in serving path-style GET, HEAD and
+// PUT requests for objects kept in memory
+type testObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *testObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		http.Error(w, "unsigned request", http.StatusForbidden)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := readTestObjectBody(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.objects[r.URL.Path] = data
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodGet, http.MethodHead:
+		data, ok := s.objects[r.URL.Path]
+		if !ok {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	default:
+		http.Error(w, "unsupported method", http.StatusMethodNotAllowed)
+	}
+}
+
+// readTestObjectBody reads the body of a PUT request, decoding the aws-chunked encoding
+// of streaming signed uploads
+func readTestObjectBody(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data bytes.Buffer
+	br := bufio.NewReader(r.Body)
+	for {
+		header, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.ParseInt(strings.SplitN(strings.TrimSpace(header), ";", 2)[0], 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+		if _, err := io.CopyN(&data, br, size); err != nil {
+			return nil, err
+		}
+		if _, err := br.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func TestS3(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "doc.pdf")
+	writeTestPdf(t, pdfPath, []string{"One", "Two"})
+	data, err := os.ReadFile(pdfPath)
+	if err != nil {
+		t.Fatalf("Failed to read test PDF: %v", err)
+	}
+
+	store := &testObjectStore{objects: map[string][]byte{"/docs/in/doc.pdf": data}}
+	server := httptest.NewServer(store)
+	defer server.Close()
+	cfg := &S3Config{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Region:    "us-east-1",
+		AccessKey: "test",
+		SecretKey: "testsecret",
+		Insecure:  true,
+		PathStyle: true,
+	}
+
+	bucket, key, err := ParseS3URL("s3://docs/in/doc.pdf")
+	if err != nil || bucket != "docs" || key != "in/doc.pdf" {
+		t.Fatalf("ParseS3URL = %q, %q, %v", bucket, key, err)
+	}
+	if _, _, err := ParseS3URL("https://docs/in/doc.pdf"); err == nil {
+		t.Error("Expected an error for a URL that is not s3://")
+	}
+
+	// Pages of an object are uploaded to the sink with their object URLs
+	obj, err := OpenS3Object(cfg, bucket, key)
+	if err != nil {
+		t.Fatalf("OpenS3Object failed: %v", err)
+	}
+	defer obj.Close()
+	sink, err := NewS3Sink(cfg, "pages", "/out/doc/")
+	if err != nil {
+		t.Fatalf("NewS3Sink failed: %v", err)
+	}
+	details, err := ConvertReaderWithOptions(obj, "", "doc-", &Options{DPI: 72, Sink: sink})
+	if err != nil {
+		t.Fatalf("ConvertReaderWithOptions failed: %v", err)
+	}
+	if len(details) != 2 || details[1].URL != server.URL+"/pages/out/doc/doc-1.png" {
+		t.Fatalf("Expected 2 pages with object URLs, got %d pages, last %q", len(details), details[len(details)-1].URL)
+	}
+	page, ok := store.objects["/pages/out/doc/doc-1.png"]
+	if !ok {
+		t.Fatal("Page object was not uploaded")
+	}
+	if _, err := png.Decode(bytes.NewReader(page)); err != nil {
+		t.Errorf("Uploaded page is not a PNG: %v", err)
+	}
+
+	if _, err := OpenS3Object(cfg, "docs", "in/missing.pdf"); err == nil {
+		t.Error("Expected an error for a missing object")
+	}
+	if _, err := OpenS3Object(cfg, "docs", ""); err == nil {
+		t.Error("Expected an error for an empty key")
+	}
+
+	// Keys are used as written, including characters that are special in URLs
+	store.objects["/docs/in/scan #1?v=2 100%.pdf"] = data
+	bucket, key, err = ParseS3URL("s3://docs/in/scan #1?v=2 100%.pdf")
+	if err != nil {
+		t.Fatalf("ParseS3URL failed: %v", err)
+	}
+	obj, err = OpenS3Object(cfg, bucket, key)
+	if err != nil {
+		t.Fatalf("OpenS3Object failed for %q: %v", key, err)
+	}
+	obj.Close()
+}
+
+func TestParseS3URL(t *testing.T) {
+	tests := []struct {
+		url    string
+		bucket string
+		key    string
+		valid  bool
+	}{
+		{"s3://docs/in/doc.pdf", "docs", "in/doc.pdf", true},
+		{"s3://docs/scan#1.pdf", "docs", "scan#1.pdf", true},
+		{"s3://docs/what?.pdf", "docs", "what?.pdf", true},
+		{"s3://docs/100%25.pdf", "docs", "100%25.pdf", true},
+		{"s3://docs/50%.pdf", "docs", "50%.pdf", true},
+		{"s3://docs/out/", "docs", "out/", true},
+		{"s3://docs/", "docs", "", true},
+		{"s3://docs", "docs", "", true},
+		{"s3:///doc.pdf", "", "", false},
+		{"s3://", "", "", false},
+		{"https://docs/in/doc.pdf", "", "", false},
+	}
+	for _, tt := range tests {
+		bucket, key, err := ParseS3URL(tt.url)
+		if (err == nil) != tt.valid || bucket != tt.bucket || key != tt.key {
+			t.Errorf("ParseS3URL(%q) = %q, %q, %v", tt.url, bucket, key, err)
+		}
+	}
+}
@@ -0,0 +1,409 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxUploadSize = 100 << 20
+	defaultServerTimeout = 5 * time.Minute
+	defaultMaxServerDPI  = 600
+	defaultPageTTL       = time.Hour
+	maxSweepInterval     = time.Minute
+)
+
+// ServerOptions configures the HTTP handler returned by NewHandler
+type ServerOptions struct {
+	Options *Options // Conversion options applied to every request; nil means the defaults
+
+	OutputDir string // Directory the pages of JSON responses are saved in and served from; empty means a tifpdf2png directory in os.TempDir()
+	BaseURL   string // Prefix of page URLs, such as "https://convert.example.com"; empty gives URLs relative to the server root
+
+	MaxUploadSize int64         // Largest accepted upload in bytes; 0 means 100 MiB
+	Timeout       time.Duration // Limit of each request, including waiting for a free conversion slot, checked between pages; 0 means 5 minutes
+	MaxConcurrent int           // Conversions run at a time; 0 means runtime.NumCPU()
+	MaxDPI        float64       // Highest dpi a request may ask for; 0 means 600
+	PageTTL       time.Duration // Time saved pages are kept before they are removed; 0 means 1 hour, negative keeps them until deleted
+}
+
+// convertHandler serves conversions of uploaded documents and the saved pages
+type convertHandler struct {
+	cfg   ServerOptions
+	slots chan struct{}
+	mux   *http.ServeMux
+
+	sweepMu   sync.Mutex
+	lastSweep time.Time
+}
+
+// serverResponse is the JSON response of a conversion requested with info=1
+type serverResponse struct {
+	Document *DocumentInfo  `json:"document,omitempty"`
+	Pages    []*ImageDetail `json:"pages"`
+}
+
+// NewHandler returns an http.Handler converting uploaded documents to PNG pages.
+//
+// POST /convert accepts the document as the body of the request or as the file field of
+// a multipart form, and detects its format from its content. It responds with the
+// ImageDetail JSON, whose page URLs point at GET /pages/, or with output=zip (or an
+// Accept header of application/zip) with a zip of the pages and manifest.json. The
+// query parameters pages, dpi and format override the conversion options, and info=1
+// adds the document info to the JSON; a dpi above MaxDPI is rejected. Conversions are
+// stopped between pages when the request times out or the client goes away, so a single
+// slow page can run past the timeout. Saved pages are kept in OutputDir, one directory
+// per request, until DELETE /pages/{id} removes them or PageTTL passes.
+func NewHandler(cfg *ServerOptions) http.Handler {
+	h := &convertHandler{}
+	if cfg != nil {
+		h.cfg = *cfg
+	}
+	if h.cfg.OutputDir == "" {
+		h.cfg.OutputDir = filepath.Join(os.TempDir(), "tifpdf2png")
+	}
+	if h.cfg.MaxUploadSize <= 0 {
+		h.cfg.MaxUploadSize = defaultMaxUploadSize
+	}
+	if h.cfg.Timeout <= 0 {
+		h.cfg.Timeout = defaultServerTimeout
+	}
+	if h.cfg.MaxConcurrent <= 0 {
+		h.cfg.MaxConcurrent = runtime.NumCPU()
+	}
+	if h.cfg.MaxDPI <= 0 {
+		h.cfg.MaxDPI = defaultMaxServerDPI
+	}
+	if h.cfg.PageTTL == 0 {
+		h.cfg.PageTTL = defaultPageTTL
+	}
+	h.cfg.BaseURL = strings.TrimSuffix(h.cfg.BaseURL, "/")
+	h.slots = make(chan struct{}, h.cfg.MaxConcurrent)
+
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("POST /convert", h.convert)
+	h.mux.HandleFunc("GET /pages/{id}/{name}", h.page)
+	h.mux.HandleFunc("DELETE /pages/{id}", h.deletePages)
+	return h
+}
+
+// ServeHTTP implements http.Handler
+func (h *convertHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.sweep()
+	h.mux.ServeHTTP(w, r)
+}
+
+// sweep removes the request directories saved longer than PageTTL ago. Requests trigger
+// it, at most once per PageTTL or minute, and skip it while another request sweeps.
+func (h *convertHandler) sweep() {
+	if h.cfg.PageTTL < 0 || !h.sweepMu.TryLock() {
+		return
+	}
+	defer h.sweepMu.Unlock()
+	now := time.Now()
+	if now.Sub(h.lastSweep) < min(h.cfg.PageTTL, maxSweepInterval) {
+		return
+	}
+	h.lastSweep = now
+
+	entries, err := os.ReadDir(h.cfg.OutputDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		// Leave anything that is not a request directory alone
+		if !entry.IsDir() || !isRequestID(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || now.Sub(info.ModTime()) < h.cfg.PageTTL {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(h.cfg.OutputDir, entry.Name())); err != nil {
+			slog.Warn("Failed to remove expired pages", "id", entry.Name(), "error", err)
+		}
+	}
+}
+
+// convert handles POST /convert
+func (h *convertHandler) convert(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeout)
+	defer cancel()
+
+	opts, err := h.requestOptions(r)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, err)
+		return
+	}
+	opts.OnPage = func(detail *ImageDetail) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		return h.cfg.Options.orDefault().pageSaved(detail)
+	}
+
+	body, err := uploadedDocument(w, r, h.cfg.MaxUploadSize)
+	if err != nil {
+		writeHTTPError(w, uploadErrorStatus(err), err)
+		return
+	}
+
+	select {
+	case h.slots <- struct{}{}:
+		defer func() { <-h.slots }()
+	case <-ctx.Done():
+		writeHTTPError(w, http.StatusServiceUnavailable, fmt.Errorf("no conversion slot available: %w", ctx.Err()))
+		return
+	}
+
+	if wantsZip(r) {
+		h.convertToZip(w, r, body, opts)
+		return
+	}
+
+	id, err := requestID()
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+	dir := filepath.Join(h.cfg.OutputDir, id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+	opts.Sink = urlSink{dir: DirSink(dir), base: h.cfg.BaseURL + "/pages/" + id + "/"}
+
+	pages, info, err := ConvertReaderWithDocumentInfo(body, dir, "page-", opts)
+	if err != nil {
+		if err := os.RemoveAll(dir); err != nil {
+			slog.Warn("Failed to remove output directory", "dir", dir, "error", err)
+		}
+		writeHTTPError(w, conversionErrorStatus(err), err)
+		return
+	}
+	slog.Info("Converted upload", "pages", len(pages), "dir", dir)
+
+	var response any = pages
+	if r.URL.Query().Get("info") == "1" {
+		response = serverResponse{Document: info, Pages: pages}
+	}
+	writeHTTPJSON(w, http.StatusOK, response)
+}
+
+// page handles GET /pages/{id}/{name}, serving a saved page without listing directories
+func (h *convertHandler) page(w http.ResponseWriter, r *http.Request) {
+	id, name := r.PathValue("id"), r.PathValue("name")
+	if !isRequestID(id) || name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		http.NotFound(w, r)
+		return
+	}
+	path := filepath.Join(h.cfg.OutputDir, id, name)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	http.ServeFile(w, r, path)
+}
+
+// deletePages handles DELETE /pages/{id}, removing the saved pages of a request
+func (h *convertHandler) deletePages(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	dir := filepath.Join(h.cfg.OutputDir, id)
+	if info, err := os.Stat(dir); !isRequestID(id) || err != nil || !info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// convertToZip converts an upload and responds with a zip of the pages and manifest.json
+func (h *convertHandler) convertToZip(w http.ResponseWriter, r *http.Request, body io.Reader, opts *Options) {
+	// Buffer the archive so that a failed conversion can still be reported with its status
+	var archive bytes.Buffer
+	sink := NewZipSink(&archive)
+	opts.Sink = sink
+
+	pages, info, err := ConvertReaderWithDocumentInfo(body, "", "page-", opts)
+	if err != nil {
+		writeHTTPError(w, conversionErrorStatus(err), err)
+		return
+	}
+
+	var manifest any = pages
+	if r.URL.Query().Get("info") == "1" {
+		manifest = serverResponse{Document: info, Pages: pages}
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err == nil {
+		_, err = writeSinkFile(sink, "manifest.json", append(data, '\n'))
+	}
+	if err == nil {
+		err = sink.Close()
+	}
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, err)
+		return
+	}
+	slog.Info("Converted upload", "pages", len(pages), "output", "zip")
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="pages.zip"`)
+	w.Header().Set("Content-Length", strconv.Itoa(archive.Len()))
+	if _, err := archive.WriteTo(w); err != nil {
+		slog.Warn("Failed to write zip response", "error", err)
+	}
+}
+
+// requestOptions returns the handler's conversion options with the overrides of the
+// request's query parameters
+func (h *convertHandler) requestOptions(r *http.Request) (*Options, error) {
+	opts := *h.cfg.Options.orDefault()
+	query := r.URL.Query()
+	if pages := query.Get("pages"); pages != "" {
+		opts.Pages = PageSelection(pages)
+	}
+	if dpi := query.Get("dpi"); dpi != "" {
+		value, err := strconv.ParseFloat(dpi, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dpi %q", dpi)
+		}
+		if value > h.cfg.MaxDPI {
+			return nil, fmt.Errorf("dpi %g is above the maximum of %g", value, h.cfg.MaxDPI)
+		}
+		opts.DPI = value
+	}
+	if format := query.Get("format"); format != "" {
+		opts.Format = OutputFormat(strings.ToLower(format))
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return &opts, nil
+}
+
+// uploadedDocument returns a reader of the uploaded document: the file field of a
+// multipart form, or else the body of the request, limited to maxSize bytes
+func uploadedDocument(w http.ResponseWriter, r *http.Request, maxSize int64) (io.Reader, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, errors.New("multipart form has no file field")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == "file" {
+			return part, nil
+		}
+	}
+}
+
+// wantsZip reports whether a request asks for a zip of the pages instead of JSON
+func wantsZip(r *http.Request) bool {
+	if output := r.URL.Query().Get("output"); output != "" {
+		return strings.EqualFold(output, "zip")
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/zip")
+}
+
+// urlSink writes output files into a directory and records the URLs they are served at
+type urlSink struct {
+	dir  DirSink
+	base string
+}
+
+// Create implements PageSink
+func (s urlSink) Create(name string) (io.WriteCloser, string, error) {
+	file, _, err := s.dir.Create(name)
+	if err != nil {
+		return nil, "", err
+	}
+	return file, s.base + url.PathEscape(name), nil
+}
+
+// requestID returns a random name for the output directory of a request
+func requestID() (string, error) {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id[:]), nil
+}
+
+// isRequestID reports whether a name is a request directory name returned by requestID
+func isRequestID(name string) bool {
+	id, err := hex.DecodeString(name)
+	return err == nil && len(id) == 8
+}
+
+// uploadErrorStatus returns the HTTP status for an error reading an upload
+func uploadErrorStatus(err error) int {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
+
+// conversionErrorStatus returns the HTTP status for an error converting an upload
+func conversionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, ErrUnsupportedFormat):
+		return http.StatusUnsupportedMediaType
+	}
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// writeHTTPError responds with a JSON error message
+func writeHTTPError(w http.ResponseWriter, status int, err error) {
+	slog.Warn("Conversion request failed", "status", status, "error", err)
+	writeHTTPJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// writeHTTPJSON responds with a value as JSON
+func writeHTTPJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("Failed to write JSON response", "error", err)
+	}
+}
@@ -0,0 +1,216 @@
+package tifpdf2png
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "doc.pdf")
+	writeTestPdf(t, pdfPath, []string{"One", "Two", "Three"})
+	data, err := os.ReadFile(pdfPath)
+	if err != nil {
+		t.Fatalf("Failed to read test PDF: %v", err)
+	}
+
+	server := httptest.NewServer(NewHandler(&ServerOptions{OutputDir: filepath.Join(dir, "pages"), MaxUploadSize: 1 << 20}))
+	defer server.Close()
+
+	// A multipart upload responds with page URLs served by the same server
+	var form bytes.Buffer
+	mw := multipart.NewWriter(&form)
+	part, err := mw.CreateFormFile("file", "doc.pdf")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(data)
+	mw.Close()
+
+	resp, err := http.Post(server.URL+"/convert?pages=1-2&info=1", mw.FormDataContentType(), &form)
+	if err != nil {
+		t.Fatalf("POST /convert failed: %v", err)
+	}
+	var result serverResponse
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected a JSON response, got status %d and %v", resp.StatusCode, err)
+	}
+	if len(result.Pages) != 2 || result.Document == nil || result.Document.Pages != 3 {
+		t.Fatalf("Expected pages 1-2 with document info, got %d pages and %+v", len(result.Pages), result.Document)
+	}
+	if !strings.HasPrefix(result.Pages[0].URL, "/pages/") || !strings.HasSuffix(result.Pages[0].URL, "/page-0.png") {
+		t.Fatalf("Unexpected page URL %q", result.Pages[0].URL)
+	}
+
+	resp, err = http.Get(server.URL + result.Pages[1].URL)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", result.Pages[1].URL, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("Expected the saved page, got status %d and %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	resp, err = http.Get(server.URL + "/pages/")
+	if err != nil {
+		t.Fatalf("GET /pages/ failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected no directory listing, got status %d", resp.StatusCode)
+	}
+
+	// A raw upload responds with a zip of the pages and manifest.json
+	resp, err = http.Post(server.URL+"/convert?output=zip", "application/pdf", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("POST /convert?output=zip failed: %v", err)
+	}
+	var archive bytes.Buffer
+	archive.ReadFrom(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/zip" {
+		t.Fatalf("Expected a zip response, got status %d: %s", resp.StatusCode, archive.String())
+	}
+	zr, err := zip.NewReader(bytes.NewReader(archive.Bytes()), int64(archive.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read zip response: %v", err)
+	}
+	var names []string
+	for _, file := range zr.File {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, ",") != "page-0.png,page-1.png,page-2.png,manifest.json" {
+		t.Errorf("Unexpected zip members %v", names)
+	}
+
+	// Oversized, unrecognized and invalid requests fail with their status
+	for _, tc := range []struct {
+		query  string
+		body   []byte
+		status int
+	}{
+		{"", make([]byte, 2<<20), http.StatusRequestEntityTooLarge},
+		{"", []byte("plain text notes"), http.StatusUnsupportedMediaType},
+		{"?pages=x", data, http.StatusBadRequest},
+		{"?dpi=5000", data, http.StatusBadRequest},
+	} {
+		resp, err := http.Post(server.URL+"/convert"+tc.query, "application/octet-stream", bytes.NewReader(tc.body))
+		if err != nil {
+			t.Fatalf("POST /convert%s failed: %v", tc.query, err)
+		}
+		var body map[string]string
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.status || body["error"] == "" {
+			t.Errorf("POST /convert%s: expected status %d with an error, got %d and %v", tc.query, tc.status, resp.StatusCode, body)
+		}
+	}
+}
+
+func TestHandlerTimeout(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "doc.pdf")
+	writeTestPdf(t, pdfPath, []string{"One", "Two"})
+	data, err := os.ReadFile(pdfPath)
+	if err != nil {
+		t.Fatalf("Failed to read test PDF: %v", err)
+	}
+
+	// The conversion stops at the first page once the request has timed out
+	handler := NewHandler(&ServerOptions{OutputDir: dir, Timeout: 1})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/convert", bytes.NewReader(data)))
+	if rec.Code != http.StatusServiceUnavailable && rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("Expected a timeout status, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandlerPageCleanup(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "doc.pdf")
+	writeTestPdf(t, pdfPath, []string{"One"})
+	data, err := os.ReadFile(pdfPath)
+	if err != nil {
+		t.Fatalf("Failed to read test PDF: %v", err)
+	}
+	outputDir := filepath.Join(dir, "pages")
+	handler := NewHandler(&ServerOptions{OutputDir: outputDir, PageTTL: 50 * time.Millisecond})
+
+	convert := func() string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/convert?dpi=72", bytes.NewReader(data)))
+		var pages []*ImageDetail
+		if err := json.NewDecoder(rec.Body).Decode(&pages); err != nil || rec.Code != http.StatusOK || len(pages) != 1 {
+			t.Fatalf("Expected a page, got status %d and %v", rec.Code, err)
+		}
+		return strings.Split(pages[0].URL, "/")[2]
+	}
+	request := func(method string, target string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec.Code
+	}
+
+	// Only files directly in a request's directory are served, whatever the encoding
+	id := convert()
+	if err := os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(outputDir, "other"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, "other", "page-0.png"), []byte("x"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if status := request(http.MethodGet, "/pages/"+id+"/page-0.png"); status != http.StatusOK {
+		t.Errorf("Expected the saved page, got status %d", status)
+	}
+	for _, target := range []string{
+		"/pages/other/page-0.png",
+		"/pages/%2e%2e/secret.txt",
+		"/pages/" + id + "/%2e%2e%2f%2e%2e%2fsecret.txt",
+		"/pages/" + id + "/..%5c..%5csecret.txt",
+	} {
+		if status := request(http.MethodGet, target); status != http.StatusNotFound {
+			t.Errorf("GET %s: expected status 404, got %d", target, status)
+		}
+	}
+	os.RemoveAll(filepath.Join(outputDir, "other"))
+
+	// DELETE removes the pages of a request
+	if status := request(http.MethodDelete, "/pages/"+id); status != http.StatusNoContent {
+		t.Errorf("Expected DELETE to succeed, got status %d", status)
+	}
+	if status := request(http.MethodGet, "/pages/"+id+"/page-0.png"); status != http.StatusNotFound {
+		t.Errorf("Expected a deleted page to be gone, got status %d", status)
+	}
+	for _, target := range []string{"/pages/" + id, "/pages/other"} {
+		if status := request(http.MethodDelete, target); status != http.StatusNotFound {
+			t.Errorf("DELETE %s: expected status 404, got %d", target, status)
+		}
+	}
+
+	// Pages older than PageTTL are swept by a later request, leaving other directories alone
+	id = convert()
+	if err := os.Mkdir(filepath.Join(outputDir, "other"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	request(http.MethodGet, "/pages/"+id+"/page-0.png")
+	if _, err := os.Stat(filepath.Join(outputDir, id)); !os.IsNotExist(err) {
+		t.Errorf("Expected expired pages to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "other")); err != nil {
+		t.Errorf("Expected other directories to be kept: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package tifpdf2png
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// PageSink receives the files written by a conversion. Create returns a writer for the
+// named output file and the URL recorded for it in ImageDetail.URL; the file is complete
+// once the writer is closed. A sink may be shared by concurrent conversions.
+type PageSink interface {
+	Create(name string) (io.WriteCloser, string, error)
+}
+
+// DirSink writes output files into a directory on disk, with their paths as URLs
+type DirSink string
+
+// Create implements PageSink
+func (d DirSink) Create(name string) (io.WriteCloser, string, error) {
+	path := filepath.Join(string(d), name)
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return file, path, nil
+}
+
+// writeSinkFile writes a file to a sink and returns its URL
+func writeSinkFile(sink PageSink, name string, data []byte) (string, error) {
+	file, url, err := sink.Create(name)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(data); err != nil {
+		if err := file.Close(); err != nil {
+			slog.Warn("Failed to close output file", "error", err)
+		}
+		return "", err
+	}
+	return url, file.Close()
+}
+
+// ArchiveSink writes output files as the members of a tar or zip stream, with their
+// member names as URLs. Each file is buffered until its writer is closed, and Close
+// must be called to finish the archive.
+type ArchiveSink struct {
+	mu      sync.Mutex
+	tw      *tar.Writer
+	zw      *zip.Writer
+	modTime time.Time
+}
+
+// NewTarSink returns a sink writing a tar stream to w
+func NewTarSink(w io.Writer) *ArchiveSink {
+	return &ArchiveSink{tw: tar.NewWriter(w), modTime: time.Now()}
+}
+
+// NewZipSink returns a sink writing a zip stream to w
+func NewZipSink(w io.Writer) *ArchiveSink {
+	return &ArchiveSink{zw: zip.NewWriter(w), modTime: time.Now()}
+}
+
+// Create implements PageSink
+func (s *ArchiveSink) Create(name string) (io.WriteCloser, string, error) {
+	name = filepath.ToSlash(name)
+	return &archiveMember{sink: s, name: name}, name, nil
+}
+
+// Close writes the end of the archive
+func (s *ArchiveSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tw != nil {
+		return s.tw.Close()
+	}
+	return s.zw.Close()
+}
+
+// add writes a member to the archive
+func (s *ArchiveSink) add(name string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tw != nil {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: s.modTime, Typeflag: tar.TypeReg}
+		if err := s.tw.WriteHeader(header); err != nil {
+			return err
+		}
+		_, err := s.tw.Write(data)
+		return err
+	}
+
+	w, err := s.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: s.modTime})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// archiveMember buffers an output file until it is added to the archive on Close
+type archiveMember struct {
+	sink   *ArchiveSink
+	name   string
+	buf    bytes.Buffer
+	closed bool
+}
+
+// Write implements io.Writer
+func (m *archiveMember) Write(p []byte) (int, error) {
+	if m.closed {
+		return 0, errors.New("write to closed archive member")
+	}
+	return m.buf.Write(p)
+}
+
+// Close implements io.Closer
+func (m *archiveMember) Close() error {
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	return m.sink.add(m.name, m.buf.Bytes())
+}
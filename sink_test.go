@@ -0,0 +1,77 @@
+package tifpdf2png
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestArchiveSinks(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "scan.tif")
+	writeRawTestTiff(t, tiffPath, []testTiffPage{
+		{width: 2, height: 2, pixels: []byte{0, 255, 255, 255}},
+		{width: 3, height: 1, pixels: []byte{0, 0, 255}},
+	})
+
+	// Nothing is written to destpath when a sink is set
+	var tarData bytes.Buffer
+	sink := NewTarSink(&tarData)
+	details, err := ConvertTiffToPngWithOptions(tiffPath, "", "scan-", &Options{Sink: sink})
+	if err != nil {
+		t.Fatalf("ConvertTiffToPngWithOptions failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Failed to close tar sink: %v", err)
+	}
+	if len(details) != 2 || details[0].URL != "scan-0.png" || details[1].URL != "scan-1.png" {
+		t.Fatalf("Expected archive member URLs, got %d details", len(details))
+	}
+
+	tr := tar.NewReader(&tarData)
+	for _, detail := range details {
+		header, err := tr.Next()
+		if err != nil {
+			t.Fatalf("Failed to read tar member: %v", err)
+		}
+		if header.Name != detail.URL {
+			t.Errorf("Expected member %q, got %q", detail.URL, header.Name)
+		}
+		img, err := png.Decode(tr)
+		if err != nil {
+			t.Fatalf("Failed to decode %s: %v", header.Name, err)
+		}
+		if img.Bounds().Dx() != detail.Width || img.Bounds().Dy() != detail.Height {
+			t.Errorf("%s: expected %dx%d, got %v", header.Name, detail.Width, detail.Height, img.Bounds())
+		}
+	}
+	if _, err := tr.Next(); err != io.EOF {
+		t.Errorf("Expected 2 tar members, got more (%v)", err)
+	}
+
+	var zipData bytes.Buffer
+	sink = NewZipSink(&zipData)
+	if _, err := ConvertTiffToPngWithOptions(tiffPath, "", "scan-", &Options{Sink: sink, Pages: "2"}); err != nil {
+		t.Fatalf("ConvertTiffToPngWithOptions failed: %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Failed to close zip sink: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(zipData.Bytes()), int64(zipData.Len()))
+	if err != nil {
+		t.Fatalf("Failed to read zip: %v", err)
+	}
+	if len(zr.File) != 1 || zr.File[0].Name != "scan-1.png" {
+		t.Errorf("Expected a single scan-1.png member, got %d members", len(zr.File))
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected only the input in %s, got %d entries (%v)", dir, len(entries), err)
+	}
+}
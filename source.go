@@ -0,0 +1,128 @@
+package tifpdf2png
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gen2brain/go-fitz"
+)
+
+// source is the input of a conversion: a file on disk, or a document read into memory
+type source struct {
+	path string // Filename, empty for documents read into memory
+	ext  string // Lower-case extension selecting the converter, such as ".pdf"
+	data []byte // Contents of documents read into memory
+}
+
+// fileSource returns the source for a file on disk
+func fileSource(filename string) *source {
+	return &source{path: filename, ext: strings.ToLower(filepath.Ext(filename))}
+}
+
+// memorySource returns the source for a document read into memory, with the extension
+// of its detected format
+func memorySource(data []byte, ext string) *source {
+	return &source{ext: ext, data: data}
+}
+
+// inMemory reports whether the document was read into memory
+func (s *source) inMemory() bool {
+	return s.data != nil
+}
+
+// open returns a seekable reader of the contents
+func (s *source) open() (io.ReadSeekCloser, error) {
+	if s.inMemory() {
+		return nopSeekCloser{bytes.NewReader(s.data)}, nil
+	}
+	return os.Open(s.path)
+}
+
+// read returns the contents
+func (s *source) read() ([]byte, error) {
+	if s.inMemory() {
+		return s.data, nil
+	}
+	return os.ReadFile(s.path)
+}
+
+// openFitz opens the document with MuPDF, which detects the format of documents in
+// memory from their content and of files from their extension
+func (s *source) openFitz() (*fitz.Document, error) {
+	if s.inMemory() {
+		return fitz.NewFromMemory(s.data)
+	}
+	return fitz.New(s.path)
+}
+
+// DetectFormat returns the extension of a supported format, such as ".pdf", detected
+// from the content of a document, or "" if the content is not recognized
+func DetectFormat(data []byte) string {
+	head := data[:min(len(data), 1024)]
+	switch {
+	case bytes.HasPrefix(data, []byte("II*\x00")), bytes.HasPrefix(data, []byte("MM\x00*")),
+		bytes.HasPrefix(data, []byte("II+\x00")), bytes.HasPrefix(data, []byte("MM\x00+")):
+		return ".tif"
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return ".jpg"
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return ".png"
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return ".gif"
+	case len(data) >= 12 && bytes.HasPrefix(data, []byte("RIFF")) && string(data[8:12]) == "WEBP":
+		return ".webp"
+	case bytes.HasPrefix(data, []byte("\x97JB2\r\n\x1a\n")):
+		return ".jb2"
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return zipFormat(data)
+	case len(data) >= 68 && string(data[60:68]) == "BOOKMOBI":
+		return ".mobi"
+	case bytes.Contains(head, []byte("%PDF-")):
+		return ".pdf"
+	case bytes.Contains(head, []byte("<FictionBook")):
+		return ".fb2"
+	case bytes.HasPrefix(data, []byte("BM")) && len(data) >= 26:
+		return ".bmp"
+	}
+	return ""
+}
+
+// zipFormat tells EPUB, XPS and CBZ documents apart by the members of their zip archive
+func zipFormat(data []byte) string {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ""
+	}
+
+	images := false
+	for _, file := range archive.File {
+		name := strings.ToLower(file.Name)
+		switch {
+		case name == "mimetype":
+			return ".epub"
+		case strings.HasSuffix(name, ".fdseq"), strings.HasSuffix(name, ".fdoc"), strings.HasSuffix(name, ".fpage"):
+			return ".xps"
+		case rasterImageFormats[path.Ext(name)] != "":
+			images = true
+		}
+	}
+	if images {
+		return ".cbz"
+	}
+	return ""
+}
+
+// nopSeekCloser is an io.ReadSeeker with a Close method that does nothing
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+// Close implements io.Closer
+func (nopSeekCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,63 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertTiffSubImages(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "pyramid.tif")
+
+	gray := func(width, height int) []byte {
+		return bytes.Repeat([]byte{0x40}, width*height)
+	}
+	writeRawTestTiff(t, tiffPath, []testTiffPage{
+		{
+			width: 16, height: 8, pixels: gray(16, 8),
+			subImages: []testTiffPage{
+				{width: 32, height: 16, pixels: gray(32, 16)},
+				{width: 8, height: 4, pixels: gray(8, 4)},
+			},
+		},
+		{width: 16, height: 8, pixels: gray(16, 8)},
+	})
+
+	for _, tc := range []struct {
+		mode      SubImageMode
+		subImages []int
+		widths    []int
+		files     []string
+	}{
+		{"", []int{0, 0}, []int{16, 16}, []string{"0.png", "1.png"}},
+		{SubImagesAll, []int{0, 1, 2, 0}, []int{16, 32, 8, 16}, []string{"0.png", "0-1.png", "0-2.png", "1.png"}},
+		{SubImagesLargest, []int{1, 0}, []int{32, 16}, []string{"0.png", "1.png"}},
+	} {
+		prefix := "sub-" + string(tc.mode) + "-"
+		details, err := ConvertTiffToPngWithOptions(tiffPath, dir, prefix, &Options{SubImages: tc.mode})
+		if err != nil {
+			t.Fatalf("%q: ConvertTiffToPngWithOptions failed: %v", tc.mode, err)
+		}
+		if len(details) != len(tc.subImages) {
+			t.Fatalf("%q: expected %d images, got %d", tc.mode, len(tc.subImages), len(details))
+		}
+
+		for k, detail := range details {
+			if detail.SubImage != tc.subImages[k] || detail.Width != tc.widths[k] {
+				t.Errorf("%q image %d: expected sub-image %d of width %d, got %d of width %d",
+					tc.mode, k, tc.subImages[k], tc.widths[k], detail.SubImage, detail.Width)
+			}
+			if filepath.Base(detail.URL) != prefix+tc.files[k] {
+				t.Errorf("%q image %d: expected file %s, got %s", tc.mode, k, prefix+tc.files[k], filepath.Base(detail.URL))
+			}
+			if detail.Pages != 2 {
+				t.Errorf("%q image %d: expected 2 pages, got %d", tc.mode, k, detail.Pages)
+			}
+		}
+	}
+
+	if _, err := ConvertTiffToPngWithOptions(tiffPath, dir, "sub-", &Options{SubImages: "smallest"}); err == nil {
+		t.Error("Expected error for unsupported sub-image mode")
+	}
+}
@@ -0,0 +1,308 @@
+package tifpdf2png
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gen2brain/go-fitz"
+)
+
+// pdfPointsPerInch is the resolution of PDF user space coordinates
+const pdfPointsPerInch = 72.0
+
+// averageGlyphWidth is the estimated advance of a glyph as a fraction of the font size,
+// used for words that are not drawn as glyphs, such as the invisible text of OCR layers
+const averageGlyphWidth = 0.5
+
+// wordGapFactor is the gap between glyphs, as a fraction of the font size, that separates
+// words drawn without a space between them
+const wordGapFactor = 0.25
+
+var (
+	htmlLinePattern = regexp.MustCompile(`<p style="top:([0-9.]+)pt;left:([0-9.]+)pt;line-height:([0-9.]+)pt">(.*?)</p>`)
+	htmlSpanPattern = regexp.MustCompile(`<span style="[^"]*?font-size:([0-9.]+)pt[^"]*">(.*?)</span>`)
+	htmlTagPattern  = regexp.MustCompile(`<[^>]*>`)
+
+	svgGlyphPathPattern = regexp.MustCompile(`<path id="([^"]+)" d="([^"]*)"`)
+	svgGlyphUsePattern  = regexp.MustCompile(`<use data-text="([^"]*)" xlink:href="#([^"]+)" transform="matrix\(([^)]*)\)"`)
+	svgNumberPattern    = regexp.MustCompile(`[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?`)
+)
+
+// textLine is a line of text positioned in PDF points
+type textLine struct {
+	top, left, height float64
+	spans             []textSpan
+}
+
+// textSpan is a run of text sharing one font size
+type textSpan struct {
+	fontSize float64
+	text     string
+}
+
+// svgGlyph is a glyph drawn on a page, in PDF points with y growing downwards
+type svgGlyph struct {
+	text              string
+	x, baseline, size float64 // Pen position and font size
+	inkLeft, inkRight float64 // Horizontal extent of the glyph outline
+}
+
+// glyphWord is a word drawn as glyphs, from the pen position of its first glyph to the
+// pen position following its last glyph
+type glyphWord struct {
+	text           string
+	left, right    float64
+	baseline, size float64
+	used           bool
+}
+
+// extractPageText extracts the text layer of a page and maps word boxes into the
+// coordinate space of the rendered (and possibly cropped) PNG
+func extractPageText(doc *fitz.Document, pageNum int, dpi float64, cropInfo CropInfo, includeHTML bool) (*PageText, error) {
+	content, err := doc.Text(pageNum)
+	if err != nil {
+		return nil, err
+	}
+
+	pageHTML, err := doc.HTML(pageNum, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// The SVG output draws each visible glyph at its position, which the HTML lacks
+	pageSVG, err := doc.SVG(pageNum)
+	if err != nil {
+		return nil, err
+	}
+	glyphWords := glyphWordsFromSVG(pageSVG)
+
+	pageText := &PageText{
+		Content: content,
+		Words:   wordBoxesFromLines(parseTextLines(pageHTML), glyphWords, dpi/pdfPointsPerInch, cropInfo.OffsetX, cropInfo.OffsetY),
+	}
+	if includeHTML {
+		pageText.HTML = pageHTML
+	}
+
+	return pageText, nil
+}
+
+// parseTextLines parses the positioned lines out of MuPDF's structured text HTML
+func parseTextLines(pageHTML string) []textLine {
+	var lines []textLine
+
+	for _, m := range htmlLinePattern.FindAllStringSubmatch(pageHTML, -1) {
+		line := textLine{
+			top:    parsePoints(m[1]),
+			left:   parsePoints(m[2]),
+			height: parsePoints(m[3]),
+		}
+
+		for _, s := range htmlSpanPattern.FindAllStringSubmatch(m[4], -1) {
+			line.spans = append(line.spans, textSpan{
+				fontSize: parsePoints(s[1]),
+				text:     html.UnescapeString(htmlTagPattern.ReplaceAllString(s[2], "")),
+			})
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// wordBoxesFromLines splits lines into words and converts their boxes from PDF points
+// into pixels, relative to the crop offset. The horizontal extent of each word comes from
+// the matching glyph word on the line; words without one, such as invisible text, are
+// estimated from the font size and marked as estimated. The vertical extent is the line's.
+func wordBoxesFromLines(lines []textLine, glyphWords []*glyphWord, scale float64, offsetX, offsetY int) []WordBox {
+	byText := make(map[string][]*glyphWord)
+	for _, w := range glyphWords {
+		byText[w.text] = append(byText[w.text], w)
+	}
+
+	var words []WordBox
+
+	for _, line := range lines {
+		x := line.left
+		for _, span := range line.spans {
+			advance := span.fontSize * averageGlyphWidth
+
+			for _, field := range strings.SplitAfter(span.text, " ") {
+				word := strings.TrimRight(field, " ")
+				left, right := x, x+float64(utf8.RuneCountInString(word))*advance
+
+				if word != "" {
+					match := matchGlyphWord(byText[word], line, left)
+					if match != nil {
+						match.used = true
+						left, right = match.left, match.right
+					}
+					words = append(words, WordBox{
+						Text:      word,
+						X:         int(math.Round(left*scale)) - offsetX,
+						Y:         int(math.Round(line.top*scale)) - offsetY,
+						Width:     int(math.Round((right - left) * scale)),
+						Height:    int(math.Round(line.height * scale)),
+						Estimated: match == nil,
+					})
+				}
+
+				x += float64(utf8.RuneCountInString(field)) * advance
+			}
+		}
+	}
+
+	return words
+}
+
+// matchGlyphWord returns the unused glyph word whose baseline lies on the line and whose
+// left edge is nearest to x, or nil if there is none
+func matchGlyphWord(candidates []*glyphWord, line textLine, x float64) *glyphWord {
+	var best *glyphWord
+	for _, w := range candidates {
+		if w.used || w.baseline < line.top || w.baseline > line.top+line.height*1.25 {
+			continue
+		}
+		if best == nil || math.Abs(w.left-x) < math.Abs(best.left-x) {
+			best = w
+		}
+	}
+	return best
+}
+
+// glyphWordsFromSVG groups the glyphs of MuPDF's SVG output into words, splitting at
+// whitespace, at changes of baseline and at gaps wider than wordGapFactor
+func glyphWordsFromSVG(pageSVG string) []*glyphWord {
+	var words []*glyphWord
+	var current *glyphWord
+	var last svgGlyph
+
+	end := func(next *svgGlyph) {
+		if current == nil {
+			return
+		}
+		// The pen position of a following space is where the word's last advance ends
+		if next != nil && next.baseline == current.baseline && next.x > current.right {
+			current.right = next.x
+		}
+		words = append(words, current)
+		current = nil
+	}
+
+	for _, g := range parseSVGGlyphs(pageSVG) {
+		if strings.TrimSpace(g.text) == "" {
+			end(&g)
+			continue
+		}
+		if current != nil && (math.Abs(g.baseline-current.baseline) > current.size/2 ||
+			g.x < last.x || g.inkLeft > last.inkRight+current.size*wordGapFactor) {
+			end(nil)
+		}
+		if current == nil {
+			current = &glyphWord{left: g.x, right: g.inkRight, baseline: g.baseline, size: g.size}
+		} else if current.right < g.x {
+			// Up to the next glyph, the previous glyph's advance covers the word
+			current.right = g.x
+		}
+		current.text += g.text
+		current.right = max(current.right, g.inkRight)
+		last = g
+	}
+	end(nil)
+
+	return words
+}
+
+// parseSVGGlyphs returns the glyphs drawn upright by the <use> elements of MuPDF's SVG
+// output, with the extent of their outlines from the glyph paths in <defs>
+func parseSVGGlyphs(pageSVG string) []svgGlyph {
+	outlines := make(map[string][2]float64)
+	for _, m := range svgGlyphPathPattern.FindAllStringSubmatch(pageSVG, -1) {
+		if extent, ok := pathXExtent(m[2]); ok {
+			outlines[m[1]] = extent
+		}
+	}
+
+	var glyphs []svgGlyph
+	for _, m := range svgGlyphUsePattern.FindAllStringSubmatch(pageSVG, -1) {
+		var matrix []float64
+		for _, n := range svgNumberPattern.FindAllString(m[3], -1) {
+			matrix = append(matrix, parsePoints(n))
+		}
+		// Skip rotated and skewed glyphs, whose words are estimated
+		if len(matrix) != 6 || matrix[1] != 0 || matrix[2] != 0 || matrix[0] <= 0 {
+			continue
+		}
+		g := svgGlyph{
+			text:     html.UnescapeString(m[1]),
+			x:        matrix[4],
+			baseline: matrix[5],
+			size:     matrix[0],
+			inkLeft:  matrix[4],
+			inkRight: matrix[4],
+		}
+		if extent, ok := outlines[m[2]]; ok {
+			g.inkLeft, g.inkRight = matrix[4]+extent[0]*matrix[0], matrix[4]+extent[1]*matrix[0]
+		}
+		glyphs = append(glyphs, g)
+	}
+	return glyphs
+}
+
+// pathXExtent returns the horizontal extent of the absolute coordinates of an SVG path,
+// reporting false for paths without any
+func pathXExtent(d string) ([2]float64, bool) {
+	extent := [2]float64{math.Inf(1), math.Inf(-1)}
+	add := func(x float64) {
+		extent[0], extent[1] = min(extent[0], x), max(extent[1], x)
+	}
+
+	var command byte
+	coordinate := 0
+	for i := 0; i < len(d); {
+		c := d[i]
+		switch {
+		case c == ' ' || c == ',':
+			i++
+		case strings.IndexByte("MLCQSTHVZ", c) >= 0:
+			command, coordinate = c, 0
+			i++
+		default:
+			n := svgNumberPattern.FindString(d[i:])
+			if n == "" {
+				// Relative or arc commands are not written for glyphs
+				return extent, false
+			}
+			v := parsePoints(n)
+			switch command {
+			case 'H':
+				add(v)
+			case 'M', 'L', 'C', 'Q', 'S', 'T':
+				if coordinate%2 == 0 {
+					add(v)
+				}
+				coordinate++
+			}
+			i += len(n)
+		}
+	}
+	// Glyphs such as spaces have an empty outline at their origin
+	if extent[0] > extent[1] || extent[0] == 0 && extent[1] == 0 {
+		return extent, false
+	}
+	return extent, true
+}
+
+// parsePoints parses a point value, returning zero for malformed input
+func parsePoints(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
@@ -0,0 +1,123 @@
+package tifpdf2png
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTextLines(t *testing.T) {
+	pageHTML := `<div id="page0" style="width:200.0pt;height:100.0pt">
+<p style="top:45.6pt;left:20.0pt;line-height:18.0pt"><span style="font-family:Arial,sans-serif;font-size:18.0pt">Hello &amp; <b>World</b></span></p>
+</div>`
+
+	lines := parseTextLines(pageHTML)
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 line, got %d", len(lines))
+	}
+
+	// Without glyphs, such as for invisible text, word widths are estimated
+	words := wordBoxesFromLines(lines, nil, 2.0, 10, 5)
+	if len(words) != 3 {
+		t.Fatalf("Expected 3 words, got %d: %+v", len(words), words)
+	}
+
+	want := []WordBox{
+		{Text: "Hello", X: 30, Y: 86, Width: 90, Height: 36, Estimated: true},
+		{Text: "&", X: 138, Y: 86, Width: 18, Height: 36, Estimated: true},
+		{Text: "World", X: 174, Y: 86, Width: 90, Height: 36, Estimated: true},
+	}
+	for i, w := range want {
+		if words[i] != w {
+			t.Errorf("Word %d: expected %+v, got %+v", i, w, words[i])
+		}
+	}
+}
+
+func TestWordBoxesFromGlyphs(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "glyphs.pdf")
+	writeTestPdf(t, pdfPath, []string{"Hello WWW iii"})
+
+	details, err := ConvertPdfToPngWithOptions(pdfPath, dir, "glyphs-", &Options{DPI: 72, NoCrop: true, ExtractText: true})
+	if err != nil {
+		t.Fatalf("ConvertPdfToPngWithOptions failed: %v", err)
+	}
+
+	// Helvetica advances in 1/1000 em: H 722, e 556, l 222, o 556, space 278, W 944, i 222.
+	// At 24pt, "Hello " advances 61.344pt and "WWW " 74.64pt; the last word ends at the
+	// outline of its last glyph, 0.15em past that glyph's pen position.
+	want := []WordBox{
+		{Text: "Hello", X: 72, Width: 55},
+		{Text: "WWW", X: 133, Width: 68},
+		{Text: "iii", X: 208, Width: 14},
+	}
+	words := details[0].Text.Words
+	if len(words) != len(want) {
+		t.Fatalf("Expected %d words, got %+v", len(want), words)
+	}
+	for i, w := range want {
+		got := words[i]
+		if got.Text != w.Text || got.Estimated || got.X < w.X-1 || got.X > w.X+1 || got.Width < w.Width-1 || got.Width > w.Width+1 {
+			t.Errorf("Word %d: expected %q at x=%d with width %d, got %+v", i, w.Text, w.X, w.Width, got)
+		}
+	}
+
+	// Invisible text, as in OCR layers, is not drawn, so its words are estimated
+	ocrPath := filepath.Join(dir, "ocr.pdf")
+	writeRawTestPdf(t, ocrPath, []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [4 0 R] /Count 1 >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 5 0 R /Resources << /Font << /F1 3 0 R >> >> >>",
+		testPdfStream("", "BT 3 Tr /F1 24 Tf 72 696 Td (Hidden) Tj ET"),
+	})
+	details, err = ConvertPdfToPngWithOptions(ocrPath, dir, "ocr-", &Options{DPI: 72, NoCrop: true, ExtractText: true})
+	if err != nil {
+		t.Fatalf("ConvertPdfToPngWithOptions failed: %v", err)
+	}
+	words = details[0].Text.Words
+	if len(words) != 1 || words[0].Text != "Hidden" || !words[0].Estimated {
+		t.Errorf("Expected an estimated word for invisible text, got %+v", words)
+	}
+}
+
+func TestConvertPdfToPngWithTextExtraction(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "text.pdf")
+	writeTestPdf(t, pdfPath, []string{"First page", "Second page"})
+
+	details, err := ConvertPdfToPngWithOptions(pdfPath, dir, "text-", &Options{ExtractText: true})
+	if err != nil {
+		t.Fatalf("ConvertPdfToPngWithOptions failed: %v", err)
+	}
+
+	if len(details) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(details))
+	}
+
+	for i, expected := range []string{"First page", "Second page"} {
+		text := details[i].Text
+		if text == nil {
+			t.Fatalf("Page %d: expected text layer, got nil", i+1)
+		}
+		if !strings.Contains(text.Content, expected) {
+			t.Errorf("Page %d: expected text %q, got %q", i+1, expected, text.Content)
+		}
+		if text.HTML != "" {
+			t.Errorf("Page %d: expected no HTML without IncludeHTML", i+1)
+		}
+		if len(text.Words) != 2 {
+			t.Fatalf("Page %d: expected 2 words, got %d", i+1, len(text.Words))
+		}
+
+		// Text is drawn 72pt (one inch) from the left edge of the page
+		first := text.Words[0]
+		if first.X != int(pdfRenderDPI) {
+			t.Errorf("Page %d: expected first word at x=%d, got %d", i+1, int(pdfRenderDPI), first.X)
+		}
+		if first.Y <= 0 || first.Y > details[i].Height {
+			t.Errorf("Page %d: first word y=%d outside page height %d", i+1, first.Y, details[i].Height)
+		}
+	}
+}
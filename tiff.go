@@ -2,111 +2,288 @@ package tifpdf2png
 
 import (
 	"fmt"
+	"image"
 	"log/slog"
-	"os"
 	"path/filepath"
 	"strconv"
 	"time"
-
-	tiff "github.com/dhushon/tiff"
 )
 
 // ConvertTiffToPngWithImageDetails converts TIFF to PNG and returns ImageDetail slice
 func ConvertTiffToPngWithImageDetails(tiffFilename string, destpath string, prefix string) ([]*ImageDetail, error) {
-	file, err := os.Open(tiffFilename)
+	return ConvertTiffToPngWithOptions(tiffFilename, destpath, prefix, nil)
+}
+
+// ConvertTiffToPngWithOptions converts TIFF to PNG using the given options and returns
+// ImageDetail slice, including the tags of each page. Options that only apply to PDFs
+// are ignored.
+func ConvertTiffToPngWithOptions(tiffFilename string, destpath string, prefix string, opts *Options) ([]*ImageDetail, error) {
+	return convertTiff(fileSource(tiffFilename), destpath, prefix, opts)
+}
+
+// convertTiff converts every page of a TIFF
+func convertTiff(src *source, destpath string, prefix string, opts *Options) ([]*ImageDetail, error) {
+	started := time.Now()
+	opts = opts.orDefault()
+	if err := opts.Validate(); err != nil {
+		slog.Error("ConvertTiffToPngWithOptions: invalid options", "error", err)
+		return nil, err
+	}
+
+	pages, err := openTiffPages(src)
 	if err != nil {
-		slog.Error("ConvertTiffToPngWithImageDetails: load file", "error", err)
+		slog.Error("ConvertTiffToPngWithOptions: load file", "error", err)
 		return nil, err
 	}
 	defer func() {
-		if err := file.Close(); err != nil {
+		if err := pages.Close(); err != nil {
 			slog.Warn("Failed to close TIFF file", "error", err)
 		}
 	}()
 
-	frames, _, err := tiff.DecodeAll(file)
-	if err != nil {
-		slog.Error("ConvertTiffToPngWithImageDetails: decode tiff", "error", err)
-		return nil, err
-	}
-
-	if len(frames) == 0 {
-		slog.Error("ConvertTiffToPngWithImageDetails: no images found in TIFF file")
+	pageCount := pages.Len()
+	if pageCount == 0 {
+		slog.Error("ConvertTiffToPngWithOptions: no images found in TIFF file")
 		return nil, fmt.Errorf("no images found in TIFF file")
 	}
 
 	if prefix == "" {
 		prefix = time.Now().Format("20060102-150405-")
 	}
-	if destpath[len(destpath)-1:] != "/" {
-		destpath = destpath + "/"
-	}
+	sink := opts.sinkFor(destpath)
 
 	var imageDetails []*ImageDetail
 
-	for i, img := range frames {
-		if len(img) == 0 {
-			slog.Warn("ConvertTiffToPngWithImageDetails: empty image frame", "frameIndex", i)
+	for i := 0; i < pageCount; i++ {
+		if !opts.Pages.includes(i + 1) {
 			continue
 		}
-		if img[0] == nil {
-			slog.Warn("ConvertTiffToPngWithImageDetails: nil image frame", "frameIndex", i)
+		if pages.SubImageCount(i) == 0 {
+			slog.Warn("ConvertTiffToPngWithOptions: empty image frame", "frameIndex", i)
 			continue
 		}
 
-		croppedFrame, cropInfo := cropToContentWithInfo(img[0])
-		whiteBackgroundFrame := convertToWhiteBackground(croppedFrame)
+		for _, j := range selectSubImages(pages, i, opts.SubImages) {
+			outputFilename := prefix + strconv.Itoa(i) + ".png"
+			if j > 0 && opts.SubImages == SubImagesAll {
+				outputFilename = prefix + strconv.Itoa(i) + "-" + strconv.Itoa(j) + ".png"
+			}
+
+			page, err := pages.Page(i, j)
+			if err != nil {
+				slog.Error("ConvertTiffToPngWithOptions: read page",
+					"frameIndex", i,
+					"subImage", j,
+					"error", err)
+				return nil, err
+			}
+
+			imageDetail, err := convertTiffImage(page, pageCount, sink, outputFilename, opts)
+			if err != nil {
+				return nil, err
+			}
+			if imageDetail != nil {
+				if err := opts.pageSaved(imageDetail); err != nil {
+					return nil, err
+				}
+				imageDetails = append(imageDetails, imageDetail)
+			}
+		}
+	}
+
+	if opts.WriteManifest {
+		if err := writeManifest(src, destpath, opts, pageCount, started, imageDetails); err != nil {
+			slog.Error("ConvertTiffToPngWithOptions: write manifest", "error", err)
+			return nil, err
+		}
+	}
+
+	return imageDetails, nil
+}
+
+// selectSubImages returns the indexes of the sub-images of page i to convert. Index 0
+// is the page's main image and further indexes are the images of its SubIFDs.
+func selectSubImages(pages *TiffPages, i int, mode SubImageMode) []int {
+	switch mode {
+	case SubImagesAll:
+		indexes := make([]int, pages.SubImageCount(i))
+		for j := range indexes {
+			indexes[j] = j
+		}
+		return indexes
+	case SubImagesLargest:
+		largest, largestArea := 0, -1
+		for j := 0; j < pages.SubImageCount(i); j++ {
+			if pages.reader.Ifd[i][j] == nil {
+				continue
+			}
+			width, height, err := pages.imageSize(i, j)
+			if err != nil {
+				slog.Warn("selectSubImages: image config", "frameIndex", i, "subImage", j, "error", err)
+				continue
+			}
+			if area := width * height; area > largestArea {
+				largest, largestArea = j, area
+			}
+		}
+		return []int{largest}
+	default:
+		return []int{0}
+	}
+}
+
+// convertTiffImage decodes a page or sub-image, writes it as PNG and returns its
+// ImageDetail, or nil if the image is empty. The image is reoriented and converted to
+// sRGB before cropping. Pages larger than streamingPagePixels are converted block by
+// block unless they need resampling or such transforms.
+func convertTiffImage(page *TiffPage, pageCount int, sink PageSink, outputFilename string, opts *Options) (*ImageDetail, error) {
+	sourceDPI := tiffDPI(page.Info)
+	outputDPI := sourceDPI
+	resampling := sourceDPI != nil && (opts.TargetDPI > 0 || opts.SquarePixels)
+
+	var cropInfo CropInfo
+	var transforms []string
+	var url string
+	if page.Width*page.Height > streamingPagePixels && !resampling && !page.needsTransforms() {
+		var err error
+		cropInfo, url, err = saveTiffBlocksAsPng(page, sink, outputFilename, opts)
+		if err != nil {
+			slog.Error("ConvertTiffToPngWithOptions: convert page blocks",
+				"frameIndex", page.Index,
+				"subImage", page.SubImage,
+				"error", err)
+			return nil, err
+		}
+	} else {
+		img, err := page.Decode()
+		if err != nil {
+			slog.Error("ConvertTiffToPngWithOptions: decode page",
+				"frameIndex", page.Index,
+				"subImage", page.SubImage,
+				"error", err)
+			return nil, err
+		}
+		if img == nil {
+			slog.Warn("ConvertTiffToPngWithOptions: nil image frame", "frameIndex", page.Index, "subImage", page.SubImage)
+			return nil, nil
+		}
+
+		img, transforms = applyTiffTransforms(page, img)
+
+		// Resolutions follow the image axes, which orientation may have swapped
+		pixelDPI := sourceDPI
+		if pixelDPI != nil && page.Geo == nil && swapsAxes(page.Info.Orientation) {
+			pixelDPI = &Resolution{X: pixelDPI.Y, Y: pixelDPI.X}
+		}
+		img, outputDPI = resampleToSquarePixels(img, pixelDPI, opts)
 
-		outputFilename := prefix + strconv.Itoa(i) + ".png"
-		outputFilepath := destpath + outputFilename
-		err = saveImageAsPng(whiteBackgroundFrame, outputFilepath)
+		var whiteBackgroundFrame image.Image
+		whiteBackgroundFrame, cropInfo = normalizePage(img, opts)
+
+		url, err = saveImageAsPng(sink, whiteBackgroundFrame, outputFilename)
 		if err != nil {
 			return nil, err
 		}
+	}
+
+	slog.Debug("Saved file with crop info",
+		"filename", url,
+		"offsetX", cropInfo.OffsetX,
+		"offsetY", cropInfo.OffsetY,
+		"originalSize", fmt.Sprintf("%dx%d", cropInfo.OriginalWidth, cropInfo.OriginalHeight),
+		"croppedSize", fmt.Sprintf("%dx%d", cropInfo.CroppedWidth, cropInfo.CroppedHeight))
+
+	cropDetail, imageWidth, imageHeight := cropDetailFor(cropInfo)
+
+	imageDetail := &ImageDetail{
+		ActualType: "png",
+		Page:       page.Index + 1,
+		Pages:      pageCount,
+		SubImage:   page.SubImage,
+		URL:        url,
+		Width:      imageWidth,
+		Height:     imageHeight,
+		Format:     "png",
+		Quality:    95.0,
+		CropDetail: cropDetail,
+		Tiff:       page.Info,
+		SourceDPI:  sourceDPI,
+		DPI:        outputDPI,
+		Transforms: transforms,
+	}
 
-		slog.Debug("Saved file with crop info",
-			"filename", outputFilepath,
-			"offsetX", cropInfo.OffsetX,
-			"offsetY", cropInfo.OffsetY,
-			"originalSize", fmt.Sprintf("%dx%d", cropInfo.OriginalWidth, cropInfo.OriginalHeight),
-			"croppedSize", fmt.Sprintf("%dx%d", cropInfo.CroppedWidth, cropInfo.CroppedHeight))
-
-		var cropDetail *CropDetail
-		var imageWidth, imageHeight int
-
-		if cropInfo.CroppedWidth != cropInfo.OriginalWidth || cropInfo.CroppedHeight != cropInfo.OriginalHeight {
-			cropDetail = &CropDetail{
-				OffsetX:        cropInfo.OffsetX,
-				OffsetY:        cropInfo.OffsetY,
-				OriginalWidth:  cropInfo.OriginalWidth,
-				OriginalHeight: cropInfo.OriginalHeight,
-				CroppedWidth:   cropInfo.CroppedWidth,
-				CroppedHeight:  cropInfo.CroppedHeight,
+	if page.Geo != nil {
+		scaleX := float64(cropInfo.OriginalWidth) / float64(page.Width)
+		scaleY := float64(cropInfo.OriginalHeight) / float64(page.Height)
+		imageDetail.Geo = page.Geo.forOutput(scaleX, scaleY, cropInfo)
+
+		if opts.WriteWorldFile && len(imageDetail.Geo.GeoTransform) != 6 {
+			// Several tiepoints without a transformation describe a warp, which a world file cannot hold
+			slog.Warn("ConvertTiffToPngWithOptions: no affine geotransform, skipping world file", "filename", imageDetail.URL)
+		} else if opts.WriteWorldFile {
+			worldFile, err := writeWorldFile(sink, outputFilename, imageDetail.Geo)
+			if err != nil {
+				slog.Error("ConvertTiffToPngWithOptions: write world file", "filename", imageDetail.URL, "error", err)
+				return nil, err
 			}
-			imageWidth = cropInfo.CroppedWidth
-			imageHeight = cropInfo.CroppedHeight
-		} else {
-			cropDetail = nil
-			imageWidth = cropInfo.OriginalWidth
-			imageHeight = cropInfo.OriginalHeight
+			imageDetail.Geo.WorldFile = worldFile
 		}
+	}
+
+	return imageDetail, nil
+}
+
+// applyTiffTransforms converts an image to sRGB and into its display orientation,
+// returning the names of the applied transforms
+func applyTiffTransforms(page *TiffPage, img image.Image) (image.Image, []string) {
+	var transforms []string
 
-		imageDetail := &ImageDetail{
-			ActualType: "png",
-			Page:       i + 1,
-			Pages:      len(frames),
-			URL:        filepath.Join(destpath, outputFilename),
-			Width:      imageWidth,
-			Height:     imageHeight,
-			Format:     "png",
-			Quality:    95.0,
-			CropDetail: cropDetail,
+	img, transform := convertToSRGB(img, page.profile)
+	if transform != "" {
+		transforms = append(transforms, transform)
+	}
+
+	if page.Geo == nil {
+		img, transform = applyOrientation(img, page.Info.Orientation)
+		if transform != "" {
+			transforms = append(transforms, transform)
 		}
+	}
+
+	if len(transforms) > 0 {
+		slog.Debug("Applied TIFF transforms", "frameIndex", page.Index, "subImage", page.SubImage, "transforms", transforms)
+	}
+	return img, transforms
+}
+
+// saveTiffBlocksAsPng crops, binarizes and writes an image while decoding it one row of
+// tiles or strips at a time, so the decoded page is never held in memory as a whole
+func saveTiffBlocksAsPng(page *TiffPage, sink PageSink, outputFilename string, opts *Options) (CropInfo, string, error) {
+	blocks := page.Blocks()
 
-		imageDetails = append(imageDetails, imageDetail)
+	bounds := blocks.Bounds()
+	content, cropInfo := bounds, CropInfo{
+		OriginalWidth:  bounds.Dx(),
+		OriginalHeight: bounds.Dy(),
+		CroppedWidth:   bounds.Dx(),
+		CroppedHeight:  bounds.Dy(),
+	}
+	if !opts.NoCrop {
+		content, cropInfo = contentBoundsWithInfo(blocks)
+		if err := blocks.Err(); err != nil {
+			return cropInfo, "", err
+		}
 	}
 
-	return imageDetails, nil
+	var output image.Image = &croppedImage{Image: blocks, bounds: content}
+	if !opts.NoBinarize {
+		output = &binarizedImage{src: output, invert: hasDarkBackground(output), threshold: opts.threshold()}
+	}
+	url, err := saveImageAsPng(sink, output, outputFilename)
+	if err != nil {
+		return cropInfo, "", err
+	}
+	return cropInfo, url, blocks.Err()
 }
 
 // ConvertTiffToPng provides a simplified interface that returns only filenames
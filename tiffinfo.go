@@ -0,0 +1,82 @@
+package tifpdf2png
+
+import (
+	"strings"
+	"time"
+
+	tiff "github.com/dhushon/tiff"
+)
+
+// tiffDateTimeLayout is the layout of the TIFF DateTime tag
+const tiffDateTimeLayout = "2006:01:02 15:04:05"
+
+// readTiffPageInfo collects the descriptive tags of a TIFF image file directory
+func readTiffPageInfo(ifd *tiff.IFD) *TiffPageInfo {
+	info := &TiffPageInfo{
+		Software: tiffStringTag(ifd, tiff.TagType_Software),
+		Make:     tiffStringTag(ifd, tiff.TagType_Make),
+		Model:    tiffStringTag(ifd, tiff.TagType_Model),
+		PageName: tiffStringTag(ifd, tiff.TagType_PageName),
+	}
+
+	if compression, ok := ifd.TagGetter().GetCompression(); ok {
+		info.Compression = strings.TrimPrefix(compression.String(), "TagValue_CompressionType_")
+	}
+	if photometric, ok := ifd.TagGetter().GetPhotometricInterpretation(); ok {
+		info.Photometric = strings.TrimPrefix(photometric.String(), "TagValue_PhotometricType_")
+	}
+
+	// The getter reports the default orientation for missing tags, so check for the entry
+	if entry, ok := ifd.EntryMap[tiff.TagType_Orientation]; ok {
+		if values := entry.GetInts(); len(values) > 0 {
+			info.Orientation = int(values[0])
+		}
+	}
+
+	info.XResolution = tiffRationalTag(ifd, tiff.TagType_XResolution)
+	info.YResolution = tiffRationalTag(ifd, tiff.TagType_YResolution)
+	if info.XResolution > 0 || info.YResolution > 0 {
+		// ResolutionUnit defaults to inches (TIFF 6.0, section 8)
+		info.ResolutionUnit = "inch"
+		if unit, ok := ifd.TagGetter().GetResolutionUnit(); ok {
+			switch unit {
+			case tiff.TagValue_ResolutionUnitType_PerCM:
+				info.ResolutionUnit = "cm"
+			case tiff.TagValue_ResolutionUnitType_None:
+				info.ResolutionUnit = "none"
+			}
+		}
+	}
+
+	// The decoder's GetDateTime builds a time without a location, so parse the tag here
+	if dateTime := tiffStringTag(ifd, tiff.TagType_DateTime); dateTime != "" {
+		info.DateTime = dateTime
+		if t, err := time.Parse(tiffDateTimeLayout, dateTime); err == nil {
+			info.DateTime = t.Format(time.RFC3339)
+		}
+	}
+
+	return info
+}
+
+// tiffStringTag returns an ASCII tag value without surrounding whitespace
+func tiffStringTag(ifd *tiff.IFD, tag tiff.TagType) string {
+	entry, ok := ifd.EntryMap[tag]
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(entry.GetString())
+}
+
+// tiffRationalTag returns the first value of a rational tag, or zero if it is missing or invalid
+func tiffRationalTag(ifd *tiff.IFD, tag tiff.TagType) float64 {
+	entry, ok := ifd.EntryMap[tag]
+	if !ok {
+		return 0
+	}
+	values := entry.GetRationals()
+	if len(values) == 0 || values[0][1] == 0 {
+		return 0
+	}
+	return float64(values[0][0]) / float64(values[0][1])
+}
@@ -0,0 +1,75 @@
+package tifpdf2png
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertTiffWithPageInfo(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "tags.tif")
+
+	pixels := bytes.Repeat([]byte{0x40}, 16*8)
+	writeRawTestTiff(t, tiffPath, []testTiffPage{
+		{
+			width: 16, height: 8, pixels: pixels,
+			tags: map[uint16]testTiffTag{
+				262: {tiffShort, []uint16{0}},
+				271: {tiffASCII, "Acme"},
+				272: {tiffASCII, "FaxMaster 3000"},
+				282: {tiffRational, [][2]uint32{{204, 1}}},
+				283: {tiffRational, [][2]uint32{{196, 2}}},
+				285: {tiffASCII, "Cover"},
+				305: {tiffASCII, "scan2tif 1.0"},
+				306: {tiffASCII, "2024:03:15 09:30:00"},
+			},
+		},
+		{
+			width: 16, height: 8, pixels: pixels,
+			tags: map[uint16]testTiffTag{
+				282: {tiffRational, [][2]uint32{{80, 1}}},
+				283: {tiffRational, [][2]uint32{{80, 1}}},
+				296: {tiffShort, []uint16{3}},
+			},
+		},
+	})
+
+	details, err := ConvertTiffToPngWithOptions(tiffPath, dir, "tags-", nil)
+	if err != nil {
+		t.Fatalf("ConvertTiffToPngWithOptions failed: %v", err)
+	}
+	if len(details) != 2 {
+		t.Fatalf("Expected 2 pages, got %d", len(details))
+	}
+
+	want := []TiffPageInfo{
+		{
+			Compression:    "None",
+			Photometric:    "WhiteIsZero",
+			XResolution:    204,
+			YResolution:    98,
+			ResolutionUnit: "inch",
+			DateTime:       "2024-03-15T09:30:00Z",
+			Software:       "scan2tif 1.0",
+			Make:           "Acme",
+			Model:          "FaxMaster 3000",
+			PageName:       "Cover",
+		},
+		{
+			Compression:    "None",
+			Photometric:    "BlackIsZero",
+			XResolution:    80,
+			YResolution:    80,
+			ResolutionUnit: "cm",
+		},
+	}
+	for i, w := range want {
+		if details[i].Tiff == nil {
+			t.Fatalf("Page %d: expected TIFF info, got nil", i+1)
+		}
+		if *details[i].Tiff != w {
+			t.Errorf("Page %d: expected %+v, got %+v", i+1, w, *details[i].Tiff)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package tifpdf2png
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"log/slog"
+
+	tiff "github.com/dhushon/tiff"
+)
+
+// streamingPagePixels is the page size above which TIFF pages are converted block by
+// block instead of being decoded in full. It is a variable so tests can lower it.
+var streamingPagePixels = 64 << 20
+
+// TiffPages iterates over the pages of a TIFF or BigTIFF file. Only the directory
+// structure is read when opening; page images are decoded when requested, so memory
+// use is bounded by a single page rather than the whole file.
+type TiffPages struct {
+	file   io.ReadSeeker // Closed by the reader
+	reader *tiff.Reader
+	next   int
+}
+
+// TiffPage is a page, or a sub-image of a page, of a TIFF file
+type TiffPage struct {
+	Index    int           // Page index (0-based)
+	SubImage int           // Sub-image index within the page (0 is the main image)
+	Width    int           // Width in pixels
+	Height   int           // Height in pixels
+	Info     *TiffPageInfo // Descriptive tags of the image
+	Geo      *GeoInfo      // Georeferencing of GeoTIFF images, describing the source pixels
+
+	file    io.ReadSeeker
+	reader  *tiff.Reader
+	ifd     *tiff.IFD
+	profile *iccProfile // Embedded ICC profile, if any
+}
+
+// OpenTiffPages opens a TIFF or BigTIFF file for page by page decoding
+func OpenTiffPages(filename string) (*TiffPages, error) {
+	return openTiffPages(fileSource(filename))
+}
+
+// openTiffPages opens a TIFF or BigTIFF file or document in memory for page by page decoding
+func openTiffPages(src *source) (*TiffPages, error) {
+	file, err := src.open()
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := tiff.OpenReader(file)
+	if err != nil {
+		if err := file.Close(); err != nil {
+			slog.Warn("Failed to close TIFF file", "error", err)
+		}
+		return nil, err
+	}
+
+	return &TiffPages{file: file, reader: reader}, nil
+}
+
+// Len returns the number of pages
+func (p *TiffPages) Len() int {
+	return p.reader.ImageNum()
+}
+
+// BigTIFF reports whether the file uses the 64-bit BigTIFF layout
+func (p *TiffPages) BigTIFF() bool {
+	return p.reader.Header.IsBigTiff()
+}
+
+// SubImageCount returns the number of images of page i, including its main image
+func (p *TiffPages) SubImageCount(i int) int {
+	return p.reader.SubImageNum(i)
+}
+
+// Next returns the main image of the next page, or io.EOF after the last page
+func (p *TiffPages) Next() (*TiffPage, error) {
+	for p.next < p.Len() {
+		i := p.next
+		p.next++
+		if p.SubImageCount(i) > 0 {
+			return p.Page(i, 0)
+		}
+	}
+	return nil, io.EOF
+}
+
+// Page returns sub-image j of page i without decoding it
+func (p *TiffPages) Page(i, j int) (*TiffPage, error) {
+	width, height, err := p.imageSize(i, j)
+	if err != nil {
+		return nil, err
+	}
+
+	ifd := p.reader.Ifd[i][j]
+	page := &TiffPage{
+		Index:    i,
+		SubImage: j,
+		Width:    width,
+		Height:   height,
+		Info:     readTiffPageInfo(ifd),
+		Geo:      readGeoInfo(ifd),
+		file:     p.file,
+		reader:   p.reader,
+		ifd:      ifd,
+	}
+
+	if entry, ok := ifd.EntryMap[tiff.TagType_ICCProfile]; ok {
+		profile, err := parseICCProfile(entry.Data)
+		if err != nil {
+			slog.Warn("TiffPages: ignoring embedded ICC profile", "frameIndex", i, "subImage", j, "error", err)
+		} else {
+			page.profile = profile
+			page.Info.ICCProfile = profile.Description
+		}
+	}
+
+	return page, nil
+}
+
+// imageSize returns the size of sub-image j of page i. The decoder has no colour model
+// for CMYK images, so their size is read from the tags.
+func (p *TiffPages) imageSize(i, j int) (int, int, error) {
+	ifd := p.reader.Ifd[i][j]
+	if isCMYK(ifd) {
+		width, _ := ifd.TagGetter().GetImageWidth()
+		height, _ := ifd.TagGetter().GetImageLength()
+		return int(width), int(height), nil
+	}
+
+	config, err := p.reader.ImageConfig(i, j)
+	if err != nil {
+		return 0, 0, err
+	}
+	return config.Width, config.Height, nil
+}
+
+// isCMYK reports whether an image is stored as separated CMYK
+func isCMYK(ifd *tiff.IFD) bool {
+	photometric, _ := ifd.TagGetter().GetPhotometricInterpretation()
+	return photometric == tiff.TagValue_PhotometricType_CMYK
+}
+
+// Close closes the underlying file
+func (p *TiffPages) Close() error {
+	// The reader closes the file it was opened with
+	return p.reader.Close()
+}
+
+// Decode decodes the whole image. CMYK images are returned as *image.CMYK.
+func (page *TiffPage) Decode() (image.Image, error) {
+	if isCMYK(page.ifd) {
+		return page.decodeCMYK()
+	}
+	return page.reader.DecodeImage(page.Index, page.SubImage)
+}
+
+// decodeCMYK decodes an 8-bit CMYK image, which the TIFF decoder does not support.
+// Extra samples such as alpha are ignored.
+func (page *TiffPage) decodeCMYK() (image.Image, error) {
+	ifd := page.ifd
+	bits, _ := ifd.TagGetter().GetBitsPerSample()
+	samples := len(bits)
+	if samples < 4 || bits[0] != 8 {
+		return nil, fmt.Errorf("unsupported CMYK layout: %v bits per sample", bits)
+	}
+	if predictor, ok := ifd.TagGetter().GetPredictor(); ok && predictor != tiff.TagValue_PredictorType_None {
+		return nil, fmt.Errorf("unsupported predictor %v for CMYK images", predictor)
+	}
+	if planar, _ := ifd.TagGetter().GetPlanarConfiguration(); planar != 1 { // Samples must be interleaved
+		return nil, fmt.Errorf("unsupported planar configuration %v for CMYK images", planar)
+	}
+
+	img := image.NewCMYK(image.Rect(0, 0, page.Width, page.Height))
+	for row := 0; row < ifd.BlocksDown(); row++ {
+		for col := 0; col < ifd.BlocksAcross(); col++ {
+			block := ifd.BlockBounds(col, row)
+			if _, err := page.file.Seek(ifd.BlockOffset(col, row), io.SeekStart); err != nil {
+				return nil, err
+			}
+			data, err := ifd.Compression().Decode(io.LimitReader(page.file, ifd.BlockCount(col, row)), block.Dx(), block.Dy())
+			if err != nil {
+				return nil, err
+			}
+
+			for y := block.Min.Y; y < min(block.Max.Y, page.Height); y++ {
+				for x := block.Min.X; x < min(block.Max.X, page.Width); x++ {
+					i := ((y-block.Min.Y)*block.Dx() + x - block.Min.X) * samples
+					if i+4 > len(data) {
+						return nil, fmt.Errorf("CMYK block %d,%d is truncated", col, row)
+					}
+					copy(img.Pix[img.PixOffset(x, y):], data[i:i+4])
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+// needsTransforms reports whether the image must be reoriented or converted to sRGB,
+// which requires decoding it in full. GeoTIFF images keep their stored orientation, as
+// their georeferencing describes the stored pixels.
+func (page *TiffPage) needsTransforms() bool {
+	if page.Info.Orientation > 1 && page.Geo == nil {
+		return true
+	}
+	if isCMYK(page.ifd) {
+		return true
+	}
+	return page.profile != nil && page.profile.hasMatrix && !page.profile.isSRGB()
+}
+
+// Blocks returns the image backed by its tiles or strips, which are decoded on access.
+// Only the most recently used row of blocks is kept in memory, so the image should be
+// read from top to bottom. Decoding errors are reported by Err.
+func (page *TiffPage) Blocks() *TiffBlockImage {
+	block := page.reader.ImageBlockBounds(page.Index, page.SubImage, 0, 0)
+	return &TiffBlockImage{
+		page:        page,
+		blockWidth:  max(1, block.Dx()),
+		blockHeight: max(1, block.Dy()),
+		row:         -1,
+		blocks:      make([]image.Image, page.reader.ImageBlocksAcross(page.Index, page.SubImage)),
+	}
+}
+
+// TiffBlockImage is an image.Image that decodes a TIFF image one tile or strip at a time
+type TiffBlockImage struct {
+	page        *TiffPage
+	blockWidth  int
+	blockHeight int
+
+	row    int           // Block row held in blocks
+	blocks []image.Image // Decoded blocks of the current row
+	err    error         // First decoding error
+}
+
+// ColorModel implements image.Image
+func (m *TiffBlockImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds implements image.Image
+func (m *TiffBlockImage) Bounds() image.Rectangle {
+	return image.Rect(0, 0, m.page.Width, m.page.Height)
+}
+
+// At implements image.Image, decoding the block containing the pixel if needed.
+// Pixels of blocks that fail to decode are transparent.
+func (m *TiffBlockImage) At(x, y int) color.Color {
+	if !(image.Point{X: x, Y: y}).In(m.Bounds()) {
+		return color.RGBA{}
+	}
+
+	col, row := x/m.blockWidth, y/m.blockHeight
+	if row != m.row {
+		m.row = row
+		clear(m.blocks)
+	}
+	if col >= len(m.blocks) {
+		return color.RGBA{}
+	}
+
+	if m.blocks[col] == nil {
+		block, err := m.page.reader.DecodeImageBlock(m.page.Index, m.page.SubImage, col, row)
+		if err != nil {
+			if m.err == nil {
+				m.err = err
+			}
+			block = image.NewRGBA(m.page.reader.ImageBlockBounds(m.page.Index, m.page.SubImage, col, row))
+		}
+		m.blocks[col] = block
+	}
+	return m.blocks[col].At(x, y)
+}
+
+// Err returns the first error encountered while decoding blocks
+func (m *TiffBlockImage) Err() error {
+	return m.err
+}
+
+// croppedImage is a view of part of an image
+type croppedImage struct {
+	image.Image
+	bounds image.Rectangle
+}
+
+// Bounds implements image.Image
+func (m *croppedImage) Bounds() image.Rectangle {
+	return m.bounds
+}
+
+// binarizedImage is a black on white view of an image, computed on access
+type binarizedImage struct {
+	src       image.Image
+	invert    bool
+	threshold uint8
+}
+
+// ColorModel implements image.Image
+func (m *binarizedImage) ColorModel() color.Model {
+	return color.RGBAModel
+}
+
+// Bounds implements image.Image
+func (m *binarizedImage) Bounds() image.Rectangle {
+	return m.src.Bounds()
+}
+
+// At implements image.Image
+func (m *binarizedImage) At(x, y int) color.Color {
+	return binarizePixel(m.src.At(x, y), m.invert, m.threshold)
+}
@@ -0,0 +1,124 @@
+package tifpdf2png
+
+import (
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+)
+
+// writeTiledTestBigTiff writes a two page tiled BigTIFF whose first page has a dark
+// rectangle on a white background
+func writeTiledTestBigTiff(t *testing.T, destPath string) {
+	t.Helper()
+
+	pixels := make([]byte, 40*24)
+	for y := 0; y < 24; y++ {
+		for x := 0; x < 40; x++ {
+			pixels[y*40+x] = 0xff
+			if x >= 8 && x < 32 && y >= 4 && y < 20 {
+				pixels[y*40+x] = 0x10
+			}
+		}
+	}
+	writeRawTestBigTiff(t, destPath, []testTiffPage{
+		{width: 40, height: 24, pixels: pixels, tileSize: 16},
+		{width: 40, height: 24, pixels: pixels},
+	})
+}
+
+func TestTiffPagesIterator(t *testing.T) {
+	tiffPath := filepath.Join(t.TempDir(), "big.tif")
+	writeTiledTestBigTiff(t, tiffPath)
+
+	pages, err := OpenTiffPages(tiffPath)
+	if err != nil {
+		t.Fatalf("OpenTiffPages failed: %v", err)
+	}
+	defer pages.Close()
+
+	if !pages.BigTIFF() {
+		t.Error("Expected BigTIFF file")
+	}
+	if pages.Len() != 2 {
+		t.Fatalf("Expected 2 pages, got %d", pages.Len())
+	}
+
+	for i := 0; i < 2; i++ {
+		page, err := pages.Next()
+		if err != nil {
+			t.Fatalf("Page %d: Next failed: %v", i, err)
+		}
+		if page.Index != i || page.Width != 40 || page.Height != 24 {
+			t.Errorf("Page %d: unexpected page %d of %dx%d", i, page.Index, page.Width, page.Height)
+		}
+
+		decoded, err := page.Decode()
+		if err != nil {
+			t.Fatalf("Page %d: Decode failed: %v", i, err)
+		}
+		blocks := page.Blocks()
+		for y := 0; y < 24; y++ {
+			for x := 0; x < 40; x++ {
+				r1, g1, b1, a1 := decoded.At(x, y).RGBA()
+				r2, g2, b2, a2 := blocks.At(x, y).RGBA()
+				if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+					t.Fatalf("Page %d: block image differs from decoded image at %d,%d", i, x, y)
+				}
+			}
+		}
+		if err := blocks.Err(); err != nil {
+			t.Errorf("Page %d: block decoding failed: %v", i, err)
+		}
+	}
+
+	if _, err := pages.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Expected io.EOF after the last page, got %v", err)
+	}
+}
+
+func TestConvertLargeTiffInBlocks(t *testing.T) {
+	dir := t.TempDir()
+	tiffPath := filepath.Join(dir, "big.tif")
+	writeTiledTestBigTiff(t, tiffPath)
+
+	decoded, err := ConvertTiffToPngWithOptions(tiffPath, dir, "decoded-", nil)
+	if err != nil {
+		t.Fatalf("ConvertTiffToPngWithOptions failed: %v", err)
+	}
+
+	defer func(pixels int) { streamingPagePixels = pixels }(streamingPagePixels)
+	streamingPagePixels = 0
+
+	streamed, err := ConvertTiffToPngWithOptions(tiffPath, dir, "streamed-", nil)
+	if err != nil {
+		t.Fatalf("ConvertTiffToPngWithOptions in blocks failed: %v", err)
+	}
+	if len(streamed) != len(decoded) {
+		t.Fatalf("Expected %d pages, got %d", len(decoded), len(streamed))
+	}
+
+	for i := range decoded {
+		if streamed[i].Width != decoded[i].Width || streamed[i].Height != decoded[i].Height {
+			t.Errorf("Page %d: expected %dx%d, got %dx%d", i+1, decoded[i].Width, decoded[i].Height, streamed[i].Width, streamed[i].Height)
+		}
+
+		want, err := loadPng(decoded[i].URL)
+		if err != nil {
+			t.Fatalf("Page %d: %v", i+1, err)
+		}
+		got, err := loadPng(streamed[i].URL)
+		if err != nil {
+			t.Fatalf("Page %d: %v", i+1, err)
+		}
+		for y := 0; y < want.Bounds().Dy(); y++ {
+			for x := 0; x < want.Bounds().Dx(); x++ {
+				wr, _, _, _ := want.At(want.Bounds().Min.X+x, want.Bounds().Min.Y+y).RGBA()
+				gr, _, _, _ := got.At(got.Bounds().Min.X+x, got.Bounds().Min.Y+y).RGBA()
+				if wr != gr {
+					t.Fatalf("Page %d: pixel %d,%d differs between block and full conversion", i+1, x, y)
+				}
+			}
+		}
+	}
+}
@@ -2,15 +2,62 @@ package tifpdf2png
 
 // ImageDetail contains detailed information about a converted image page
 type ImageDetail struct {
-	ActualType string      `json:"actual_type"`           // The actual type of the image (e.g., "png")
-	Page       int         `json:"page"`                  // Page number (1-based)
-	Pages      int         `json:"pages"`                 // Total number of pages
-	URL        string      `json:"url"`                   // Path to the output PNG file
-	Width      int         `json:"width"`                 // Width of the image in pixels
-	Height     int         `json:"height"`                // Height of the image in pixels
-	Format     string      `json:"format"`                // Image format (e.g., "png")
-	Quality    float64     `json:"quality"`               // Quality metric (0-100)
-	CropDetail *CropDetail `json:"crop_detail,omitempty"` // Crop information if cropping occurred
+	ActualType   string      `json:"actual_type"`             // The actual type of the image (e.g., "png")
+	SourceFormat string      `json:"source_format,omitempty"` // Format of a raster image input (e.g., "jpeg", "webp")
+	Page         int         `json:"page"`                    // Page number (1-based)
+	Pages        int         `json:"pages"`                   // Total number of pages
+	SubImage     int         `json:"sub_image,omitempty"`     // Index of the TIFF sub-image within the page (0 is the main image)
+	URL          string      `json:"url"`                     // Path to the output PNG file
+	Width        int         `json:"width"`                   // Width of the image in pixels
+	Height       int         `json:"height"`                  // Height of the image in pixels
+	Format       string      `json:"format"`                  // Image format (e.g., "png")
+	Quality      float64     `json:"quality"`                 // Quality metric (0-100)
+	CropDetail   *CropDetail `json:"crop_detail,omitempty"`   // Crop information if cropping occurred
+	Text         *PageText   `json:"text,omitempty"`          // Text layer of the page if extraction was requested
+
+	Annotations []Annotation `json:"annotations,omitempty"` // Annotations and form widgets if extraction was requested
+	Links       []string     `json:"links,omitempty"`       // URIs of the page's links if extraction was requested
+	Extracted   bool         `json:"extracted,omitempty"`   // Page image was extracted at native resolution rather than rendered
+
+	Tiff      *TiffPageInfo `json:"tiff,omitempty"`       // Tags of the source TIFF page
+	SourceDPI *Resolution   `json:"source_dpi,omitempty"` // Resolution of the source page, if known
+	DPI       *Resolution   `json:"dpi,omitempty"`        // Resolution of the output image, if known
+	Geo       *GeoInfo      `json:"geo,omitempty"`        // Georeferencing of GeoTIFF pages
+
+	Transforms []string `json:"transforms,omitempty"` // Orientation and colour transforms applied to the source image, in order
+}
+
+// GeoInfo contains the georeferencing of a GeoTIFF page
+type GeoInfo struct {
+	EPSG                int            `json:"epsg,omitempty"`                 // EPSG code of the projected or geographic coordinate system
+	Keys                map[string]any `json:"keys,omitempty"`                 // GeoKey directory by key name
+	Tiepoints           [][6]float64   `json:"tiepoints,omitempty"`            // Model tiepoints (I, J, K, X, Y, Z) of the source image
+	PixelScale          []float64      `json:"pixel_scale,omitempty"`          // Model pixel scale (X, Y, Z) of the source image
+	ModelTransformation []float64      `json:"model_transformation,omitempty"` // Model transformation matrix of the source image
+	GeoTransform        []float64      `json:"geotransform,omitempty"`         // Affine transform of the output image in GDAL order, adjusted for resampling and cropping
+	WorldFile           string         `json:"world_file,omitempty"`           // Path of the world file written for the output image
+}
+
+// Resolution is a horizontal and vertical resolution in dots per inch
+type Resolution struct {
+	X float64 `json:"x"` // Horizontal resolution
+	Y float64 `json:"y"` // Vertical resolution
+}
+
+// TiffPageInfo contains the descriptive tags of a TIFF page
+type TiffPageInfo struct {
+	Compression    string  `json:"compression,omitempty"`     // Compression scheme (e.g., "G4", "LZW", "None")
+	Photometric    string  `json:"photometric,omitempty"`     // Photometric interpretation (e.g., "WhiteIsZero", "RGB")
+	XResolution    float64 `json:"x_resolution,omitempty"`    // Horizontal resolution in pixels per ResolutionUnit
+	YResolution    float64 `json:"y_resolution,omitempty"`    // Vertical resolution in pixels per ResolutionUnit
+	ResolutionUnit string  `json:"resolution_unit,omitempty"` // Resolution unit: "inch", "cm" or "none"
+	DateTime       string  `json:"date_time,omitempty"`       // Creation date and time (RFC 3339 when parseable)
+	Software       string  `json:"software,omitempty"`        // Software that created the image
+	Make           string  `json:"make,omitempty"`            // Scanner or camera manufacturer
+	Model          string  `json:"model,omitempty"`           // Scanner or camera model
+	PageName       string  `json:"page_name,omitempty"`       // Name of the page
+	Orientation    int     `json:"orientation,omitempty"`     // Orientation tag (1-8), if present
+	ICCProfile     string  `json:"icc_profile,omitempty"`     // Description of the embedded ICC profile
 }
 
 // CropDetail contains information about how an image was cropped
@@ -23,6 +70,87 @@ type CropDetail struct {
 	CroppedHeight  int `json:"cropped_height"`  // Height after cropping
 }
 
+// PageText contains the text layer extracted from a PDF page
+type PageText struct {
+	Content string    `json:"content"`         // Plain text of the page
+	HTML    string    `json:"html,omitempty"`  // Positioned HTML as produced by MuPDF
+	Words   []WordBox `json:"words,omitempty"` // Words with bounding boxes in output PNG pixel coordinates
+}
+
+// WordBox contains a word of the text layer and its bounding box in output PNG pixels.
+// The horizontal extent is measured from the word's glyphs, from the pen position of the
+// first to the end of the last, and the vertical extent is that of its line. Words that
+// are not drawn, such as the invisible text of OCR layers, have their width estimated
+// from the font size and Estimated set.
+type WordBox struct {
+	Text      string `json:"text"`                // The word itself
+	X         int    `json:"x"`                   // Left edge of the word
+	Y         int    `json:"y"`                   // Top edge of the word's line
+	Width     int    `json:"width"`               // Width of the word
+	Height    int    `json:"height"`              // Height of the word (line height)
+	Estimated bool   `json:"estimated,omitempty"` // X and Width are estimated instead of measured
+}
+
+// Annotation describes a PDF annotation or form field widget on a page. The rectangle
+// is in output PNG pixels, adjusted for any crop offset.
+type Annotation struct {
+	Type       string `json:"type"`                  // Annotation subtype (e.g., "Highlight", "Widget")
+	X          int    `json:"x"`                     // Left edge of the annotation rectangle
+	Y          int    `json:"y"`                     // Top edge of the annotation rectangle
+	Width      int    `json:"width"`                 // Width of the annotation rectangle
+	Height     int    `json:"height"`                // Height of the annotation rectangle
+	Contents   string `json:"contents,omitempty"`    // Text contents of the annotation
+	FieldName  string `json:"field_name,omitempty"`  // Fully qualified form field name (widgets only)
+	FieldType  string `json:"field_type,omitempty"`  // Form field type: Tx, Btn, Ch or Sig (widgets only)
+	FieldValue string `json:"field_value,omitempty"` // Form field value (widgets only)
+	Hidden     bool   `json:"hidden,omitempty"`      // Annotation is flagged as hidden or not viewable
+	Rendered   bool   `json:"rendered"`              // Annotation appearance was drawn onto the page image
+}
+
+// FormField is a form field of a PDF document
+type FormField struct {
+	Name  string `json:"name"`            // Fully qualified field name
+	Type  string `json:"type"`            // Field type: Tx, Btn, Ch or Sig
+	Value string `json:"value,omitempty"` // Field value
+	Page  int    `json:"page"`            // Page of the field's first widget (1-based)
+}
+
+// DocumentInfo contains document level metadata of a converted document
+type DocumentInfo struct {
+	Format       string         `json:"format"`                  // Document format and version (e.g., "PDF 1.7")
+	Encryption   string         `json:"encryption,omitempty"`    // Encryption method, empty if not encrypted
+	Title        string         `json:"title,omitempty"`         // Document title
+	Author       string         `json:"author,omitempty"`        // Document author
+	Subject      string         `json:"subject,omitempty"`       // Document subject
+	Keywords     string         `json:"keywords,omitempty"`      // Document keywords
+	Creator      string         `json:"creator,omitempty"`       // Application that created the original document
+	Producer     string         `json:"producer,omitempty"`      // Application that produced the PDF
+	CreationDate string         `json:"creation_date,omitempty"` // Creation date (RFC 3339 when parseable)
+	ModDate      string         `json:"mod_date,omitempty"`      // Last modification date (RFC 3339 when parseable)
+	Pages        int            `json:"pages"`                   // Total number of pages
+	Reflowable   bool           `json:"reflowable,omitempty"`    // Pages were laid out by MuPDF, so their count depends on the layout (EPUB, FB2, MOBI)
+	PageLabels   []string       `json:"page_labels,omitempty"`   // Label of each page (e.g., "iv", "A-1")
+	PageSizes    []PageSize     `json:"page_sizes,omitempty"`    // Displayed size of each page
+	Outline      []OutlineEntry `json:"outline,omitempty"`       // Table of contents
+	FormFields   []FormField    `json:"form_fields,omitempty"`   // Form fields if annotation extraction was requested
+}
+
+// PageSize contains the displayed size of a document page in PDF points (1/72 inch): its
+// crop box, or else its media box, after the page's rotation
+type PageSize struct {
+	Page   int     `json:"page"`   // Page number (1-based)
+	Width  float64 `json:"width"`  // Width in points
+	Height float64 `json:"height"` // Height in points
+}
+
+// OutlineEntry is an entry of a document's table of contents
+type OutlineEntry struct {
+	Level int    `json:"level"`         // Nesting level (starting from 1)
+	Title string `json:"title"`         // Title of the entry
+	Page  int    `json:"page"`          // Target page number (1-based)
+	URI   string `json:"uri,omitempty"` // Link target of the entry
+}
+
 // CropInfo is an internal structure used during image processing
 type CropInfo struct {
 	OffsetX        int `json:"offset_x"`
@@ -0,0 +1,56 @@
+package tifpdf2png
+
+import (
+	"log/slog"
+	"strconv"
+
+	"github.com/gen2brain/go-fitz"
+)
+
+// savePdfPageAsVector writes a page as SVG or as positioned HTML, named like the PNG
+// output. Sizes in the returned ImageDetail are in points.
+func savePdfPageAsVector(doc *fitz.Document, pageNum int, pageCount int, sink PageSink, prefix string, format OutputFormat) (*ImageDetail, error) {
+	var content string
+	var err error
+	if format == FormatSVG {
+		content, err = doc.SVG(pageNum)
+	} else {
+		content, err = doc.HTML(pageNum, true)
+	}
+	if err != nil {
+		slog.Error("ConvertPdfToPngWithOptions: export page",
+			"page", pageNum,
+			"format", format,
+			"error", err)
+		return nil, err
+	}
+
+	bounds, err := doc.Bound(pageNum)
+	if err != nil {
+		slog.Error("ConvertPdfToPngWithOptions: page bounds", "page", pageNum, "error", err)
+		return nil, err
+	}
+
+	outputFilename := prefix + strconv.Itoa(pageNum) + "." + string(format)
+	url, err := writeSinkFile(sink, outputFilename, []byte(content))
+	if err != nil {
+		slog.Error("ConvertPdfToPngWithOptions: write page", "filename", outputFilename, "error", err)
+		return nil, err
+	}
+
+	slog.Debug("Saved PDF page as vector",
+		"filename", url,
+		"page", pageNum,
+		"format", format)
+
+	return &ImageDetail{
+		ActualType: string(format),
+		Page:       pageNum + 1,
+		Pages:      pageCount,
+		URL:        url,
+		Width:      bounds.Dx(),
+		Height:     bounds.Dy(),
+		Format:     string(format),
+		Quality:    100.0,
+	}, nil
+}
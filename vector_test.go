@@ -0,0 +1,55 @@
+package tifpdf2png
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConvertPdfToVector(t *testing.T) {
+	dir := t.TempDir()
+	pdfPath := filepath.Join(dir, "vector.pdf")
+	writeTestPdf(t, pdfPath, []string{"First page", "Second page"})
+
+	for _, tc := range []struct {
+		format OutputFormat
+		marker string
+	}{
+		{FormatSVG, "<svg"},
+		{FormatHTML, "Second"},
+	} {
+		details, err := ConvertPdfToPngWithOptions(pdfPath, dir, "vector-", &Options{Format: tc.format, ExtractText: true})
+		if err != nil {
+			t.Fatalf("%s: ConvertPdfToPngWithOptions failed: %v", tc.format, err)
+		}
+		if len(details) != 2 {
+			t.Fatalf("%s: expected 2 pages, got %d", tc.format, len(details))
+		}
+
+		detail := details[1]
+		if detail.Format != string(tc.format) || filepath.Ext(detail.URL) != "."+string(tc.format) {
+			t.Errorf("%s: unexpected format %q or file %q", tc.format, detail.Format, detail.URL)
+		}
+		if detail.Width != 612 || detail.Height != 792 || detail.CropDetail != nil {
+			t.Errorf("%s: expected uncropped 612x792pt page, got %dx%d", tc.format, detail.Width, detail.Height)
+		}
+
+		content, err := os.ReadFile(detail.URL)
+		if err != nil {
+			t.Fatalf("%s: read output: %v", tc.format, err)
+		}
+		if !strings.Contains(string(content), tc.marker) {
+			t.Errorf("%s: expected output to contain %q", tc.format, tc.marker)
+		}
+
+		// Word boxes are in points when pages are exported as vectors
+		if detail.Text == nil || len(detail.Text.Words) == 0 || detail.Text.Words[0].X != 72 {
+			t.Errorf("%s: expected first word at x=72pt, got %+v", tc.format, detail.Text)
+		}
+	}
+
+	if _, err := ConvertPdfToPngWithOptions(pdfPath, dir, "vector-", &Options{Format: "tiff"}); err == nil {
+		t.Error("Expected error for unsupported output format")
+	}
+}